@@ -1,6 +1,7 @@
 package dog_pool
 
 import "errors"
+import "time"
 
 //
 // What mode are we building the connection pool in?
@@ -16,8 +17,63 @@ const (
 	AGRESSIVE
 )
 
+//
+// What happens when AGRESSIVE connections fail to open?
+//
+type WarmUpPolicy int
+
+//
+// How tolerant is Open() of connections that fail to open?
+//
+const (
+	WARMUP_FAIL_FAST   WarmUpPolicy = iota // Open fails on the first connection error
+	WARMUP_MIN_HEALTHY                     // Open fails when less than MinHealthy of the connections open
+	WARMUP_LAZY_FILL                       // Open never fails, failed connections are replaced by lazy connections
+)
+
+//
+// Is a URL's circuit breaker failing fast?
+//
+type CircuitState int
+
+//
+// States of the circuit breaker
+//
+const (
+	CIRCUIT_CLOSED    CircuitState = iota // Connections dial the URL
+	CIRCUIT_OPEN                          // Connections fail fast with ErrCircuitOpen
+	CIRCUIT_HALF_OPEN                     // One connection is probing the URL
+)
+
+//
+// How important is a log message?
+//
+type LogLevel int
+
+//
+// Levels of the log messages, from the most verbose to the most severe
+//
+const (
+	LOG_FINEST   LogLevel = iota // Pool bookkeeping, e.g. connections returned to the pool
+	LOG_DEBUG                    // Debugging details
+	LOG_TRACE                    // Commands sent, and how long they took
+	LOG_INFO                     // Connections opened and closed, and the replies received
+	LOG_WARNING                  // Failed commands, retries and failovers
+	LOG_ERROR                    // Errors the caller should look into
+	LOG_CRITICAL                 // Panics, and the pool running out of connections
+)
+
+//
+// Timeout of the connections, pools and clusters that don't set one
+//
+const default_timeout = time.Duration(15) * time.Second
+
 //
 // Constants for connecting to Memcached/Redis
 //
 var ErrConnectionIsClosed = errors.New("Connection is closed, command aborted")
 var ErrNoConnectionsAvailable = errors.New("No Connections available")
+var ErrPoolTimeout = errors.New("Timed out waiting for a connection")
+var ErrWarmUpFailed = errors.New("Too few connections opened while warming up the pool")
+var ErrCircuitOpen = errors.New("Circuit breaker is open, command aborted")
+var ErrClusterSlotNotServed = errors.New("Cluster slot is not served by any node")
@@ -0,0 +1,120 @@
+//
+// Circuit Breaker for a backend URL written in GO
+//
+
+package dog_pool
+
+import "sync"
+import "time"
+
+//
+// Fails fast after too many consecutive errors from a URL
+//
+// A nil *circuitBreaker is valid, and never trips.
+//
+type circuitBreaker struct {
+	threshold int           "Trip after this many consecutive errors"
+	coolDown  time.Duration "How long to fail fast before letting a probe through"
+
+	mutex    sync.Mutex   "Guards the state"
+	state    CircuitState "Is the breaker failing fast?"
+	failures int          "Consecutive errors since the last success"
+	openedAt time.Time    "When the breaker tripped, or when the probe started"
+}
+
+//
+// Returns nil when the threshold is 0
+//
+func makeCircuitBreaker(threshold int, cool_down time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{threshold: threshold, coolDown: cool_down}
+}
+
+//
+// Current state of the breaker
+//
+func (p *circuitBreaker) State() CircuitState {
+	if nil == p {
+		return CIRCUIT_CLOSED
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.state
+}
+
+//
+// Could the next allow() let a connection through?
+//
+func (p *circuitBreaker) ready() bool {
+	if nil == p {
+		return true
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return CIRCUIT_CLOSED == p.state || time.Since(p.openedAt) >= p.coolDown
+}
+
+//
+// May the connection dial the URL?
+//
+// After the cool-down one caller is let through to probe the URL,
+// the probe is retried if it doesn't report back within another cool-down.
+//
+func (p *circuitBreaker) allow() (ok bool, probe bool) {
+	if nil == p {
+		return true, false
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch {
+	case CIRCUIT_CLOSED == p.state:
+		return true, false
+	case time.Since(p.openedAt) < p.coolDown:
+		return false, false
+	default:
+		p.state = CIRCUIT_HALF_OPEN
+		p.openedAt = time.Now()
+		return true, true
+	}
+}
+
+//
+// The URL answered, close the breaker
+//
+func (p *circuitBreaker) success() {
+	if nil == p {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.state = CIRCUIT_CLOSED
+	p.failures = 0
+}
+
+//
+// Dial or fatal error from the URL, trip the breaker after too many of them, or when the probe fails
+//
+func (p *circuitBreaker) failure() {
+	if nil == p {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.failures++
+	if CIRCUIT_HALF_OPEN == p.state || p.failures >= p.threshold {
+		p.state = CIRCUIT_OPEN
+		p.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,92 @@
+package dog_pool
+
+import "testing"
+import "time"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestCircuitBreakerSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(CircuitBreakerSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func CircuitBreakerSpecs(c gospec.Context) {
+	c.Specify("[CircuitBreaker] Threshold=0 disables the breaker", func() {
+		breaker := makeCircuitBreaker(0, time.Hour)
+		c.Expect(breaker, gospec.Satisfies, nil == breaker)
+
+		breaker.failure()
+		breaker.success()
+		ok, probe := breaker.allow()
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(probe, gospec.Equals, false)
+		c.Expect(breaker.ready(), gospec.Equals, true)
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_CLOSED)
+	})
+
+	c.Specify("[CircuitBreaker] Opens after Threshold consecutive errors", func() {
+		breaker := makeCircuitBreaker(3, time.Hour)
+
+		breaker.failure()
+		breaker.failure()
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_CLOSED)
+
+		// Successes reset the count
+		breaker.success()
+		breaker.failure()
+		breaker.failure()
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_CLOSED)
+
+		breaker.failure()
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_OPEN)
+		c.Expect(breaker.ready(), gospec.Equals, false)
+
+		ok, _ := breaker.allow()
+		c.Expect(ok, gospec.Equals, false)
+	})
+
+	c.Specify("[CircuitBreaker] Lets one probe through after the cool-down", func() {
+		breaker := makeCircuitBreaker(1, 10*time.Millisecond)
+		breaker.failure()
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_OPEN)
+
+		time.Sleep(20 * time.Millisecond)
+		c.Expect(breaker.ready(), gospec.Equals, true)
+
+		ok, probe := breaker.allow()
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(probe, gospec.Equals, true)
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_HALF_OPEN)
+
+		// Only one probe at a time
+		ok, _ = breaker.allow()
+		c.Expect(ok, gospec.Equals, false)
+
+		breaker.success()
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_CLOSED)
+		ok, probe = breaker.allow()
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(probe, gospec.Equals, false)
+	})
+
+	c.Specify("[CircuitBreaker] A failed probe opens the breaker again", func() {
+		breaker := makeCircuitBreaker(3, 10*time.Millisecond)
+		breaker.failure()
+		breaker.failure()
+		breaker.failure()
+
+		time.Sleep(20 * time.Millisecond)
+		ok, probe := breaker.allow()
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(probe, gospec.Equals, true)
+
+		breaker.failure()
+		c.Expect(breaker.State(), gospec.Equals, CIRCUIT_OPEN)
+		ok, _ = breaker.allow()
+		c.Expect(ok, gospec.Equals, false)
+	})
+}
@@ -4,8 +4,13 @@
 
 package dog_pool
 
+import "context"
+import "time"
+
 type InitFunction func() (interface{}, error)
 
+//
+// [Depricated, use the type-safe Pool[T] instead]
 //
 // Wrapper around a buffered Channel
 //
@@ -65,6 +70,43 @@ func (p *ConnectionPoolWrapper) GetConnection() interface{} {
 	return nil
 }
 
+//
+// Get a connection from the pool, waiting for one to be returned
+//
+// Waiting callers are served in the order they started waiting.
+//
+// Output:
+//   interface{}, nil      --> Pop'd a value from the pool
+//   nil, ErrPoolTimeout   --> The context's deadline expired first
+//   nil, context.Canceled --> The context was cancelled first
+//
+func (p *ConnectionPoolWrapper) GetConnectionContext(ctx context.Context) (interface{}, error) {
+	// Channel is not empty!
+	select {
+	case c := <-p.conn:
+		return c, nil
+	default:
+	}
+
+	// Channel is empty, wait for a connection or the context
+	select {
+	case c := <-p.conn:
+		return c, nil
+	case <-ctx.Done():
+		return nil, contextError(ctx)
+	}
+}
+
+//
+// Get a connection from the pool, waiting at most timeout for one to be returned
+//
+func (p *ConnectionPoolWrapper) GetConnectionTimeout(timeout time.Duration) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return p.GetConnectionContext(ctx)
+}
+
 //
 // Return a connection from the pool
 //
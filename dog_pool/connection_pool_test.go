@@ -1,6 +1,8 @@
 package dog_pool
 
+import "context"
 import "testing"
+import "time"
 
 type stringWrapper struct {
 	Value string "simple value"
@@ -101,3 +103,66 @@ func Test_ConnectionPool_ReleaseConnection_2(t *testing.T) {
 		return
 	}
 }
+
+//
+// ConnectionPool: GetConnectionTimeout/GetConnectionContext
+//
+
+func Test_ConnectionPool_GetConnectionTimeout_1(t *testing.T) {
+	tag := "GetConnectionTimeout - Empty Pool, Timeout Error"
+
+	pool, _ := MakeConnectionPoolWrapper(0, func() (interface{}, error) {
+		return nil, nil
+	})
+
+	// Pool contains 0 connections
+	if c, err := pool.GetConnectionTimeout(time.Millisecond * 10); c != nil || err != ErrPoolTimeout {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrPoolTimeout, c, err)
+		return
+	}
+}
+
+func Test_ConnectionPool_GetConnectionTimeout_2(t *testing.T) {
+	tag := "GetConnectionTimeout - Waits for Released Connection"
+
+	expected := &stringWrapper{Value: "Hello"}
+
+	pool, _ := MakeConnectionPoolWrapper(1, func() (interface{}, error) {
+		return expected, nil
+	})
+
+	// Pool contains 0 connections
+	client := pool.GetConnection()
+
+	// Push the connection back into the pool after a short delay
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		pool.ReleaseConnection(client)
+	}()
+
+	// Pool contains 1 connection
+	if c, err := pool.GetConnectionTimeout(time.Second); err != nil || c == nil || c.(*stringWrapper).Value != expected.Value {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, expected, c, err)
+		return
+	}
+}
+
+func Test_ConnectionPool_GetConnectionContext_1(t *testing.T) {
+	tag := "GetConnectionContext - Cancelled Context, Cancelled Error"
+
+	pool, _ := MakeConnectionPoolWrapper(0, func() (interface{}, error) {
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel()
+	}()
+
+	// Pool contains 0 connections
+	if c, err := pool.GetConnectionContext(ctx); c != nil || err != context.Canceled {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, context.Canceled, c, err)
+		return
+	}
+}
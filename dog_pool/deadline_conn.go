@@ -0,0 +1,143 @@
+//
+// Connection with per-call deadlines written in GO
+//
+
+package dog_pool
+
+import "context"
+import "net"
+import "sync"
+import "time"
+
+//
+// Sets a deadline before each Read and Write,
+// and returns a TimeoutError when the Read or Write timed out
+//
+// A context being watched caps the deadlines, and cancelling it
+// interrupts the blocked Reads and Writes and fails the ones after it.
+//
+type deadlineConn struct {
+	net.Conn
+	readTimeout  time.Duration "Deadline for each Read, 0 disables the deadlines"
+	writeTimeout time.Duration "Deadline for each Write, 0 disables the deadlines"
+
+	mutex    sync.Mutex "Guards deadline and err"
+	deadline time.Time  "(optional) Deadline of the context being watched"
+	err      error      "Why the context was cancelled, the connection is out of sync once it is set"
+}
+
+func (p *deadlineConn) Read(b []byte) (int, error) {
+	timed, err := p.setDeadline(p.Conn.SetReadDeadline, p.readTimeout)
+	if nil != err {
+		return 0, err
+	}
+
+	n, err := p.Conn.Read(b)
+	if timed && isTimeout(err) {
+		err = &TimeoutError{Op: "read", After: p.readTimeout, Err: err}
+	}
+	return n, err
+}
+
+func (p *deadlineConn) Write(b []byte) (int, error) {
+	timed, err := p.setDeadline(p.Conn.SetWriteDeadline, p.writeTimeout)
+	if nil != err {
+		return 0, err
+	}
+
+	n, err := p.Conn.Write(b)
+	if timed && isTimeout(err) {
+		err = &TimeoutError{Op: "write", After: p.writeTimeout, Err: err}
+	}
+	return n, err
+}
+
+//
+// Apply the context to the Reads and Writes until stop is called
+//
+// stop returns the context's error if it was cancelled while it was watched.
+// The Reads and Writes can time out at the context's deadline before the context notices,
+// check pastDeadlineErr after a timeout.
+//
+func (p *deadlineConn) watch(ctx context.Context) (stop func() error) {
+	deadline, _ := ctx.Deadline()
+	p.mutex.Lock()
+	p.deadline = deadline
+	p.mutex.Unlock()
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	if nil == ctx.Done() {
+		close(stopped)
+	} else {
+		go func() {
+			defer close(stopped)
+			select {
+			case <-ctx.Done():
+				p.cancel(ctx.Err())
+			case <-done:
+			}
+		}()
+	}
+
+	return func() error {
+		close(done)
+		<-stopped
+
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		p.deadline = time.Time{}
+		return p.err
+	}
+}
+
+//
+// Interrupt the blocked Reads and Writes, and fail the ones after them
+//
+func (p *deadlineConn) cancel(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.err = err
+	p.Conn.SetDeadline(time.Unix(1, 0))
+}
+
+//
+// Set the earlier of the timeout and the context's deadline,
+// returns true if the deadline is the timeout's
+//
+func (p *deadlineConn) setDeadline(set func(time.Time) error, timeout time.Duration) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if nil != p.err {
+		return false, p.err
+	}
+
+	var deadline time.Time
+	timed := time.Duration(0) != timeout
+	if timed {
+		deadline = time.Now().Add(timeout)
+	}
+	if !p.deadline.IsZero() && (deadline.IsZero() || p.deadline.Before(deadline)) {
+		deadline, timed = p.deadline, false
+	}
+	return timed, set(deadline)
+}
+
+//
+// Context's raw error, or DeadlineExceeded as soon as its deadline passed
+//
+// The context's timer can fire a little after a Read times out at the deadline.
+// Unlike contextError, DeadlineExceeded is not mapped to ErrPoolTimeout.
+//
+func pastDeadlineErr(ctx context.Context) error {
+	if err := ctx.Err(); nil != err {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package dog_pool
+
+import "context"
+import "net"
+import "testing"
+import "time"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestDeadlineConnSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(DeadlineConnSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func DeadlineConnSpecs(c gospec.Context) {
+	c.Specify("[DeadlineConn] Times out the Reads", func() {
+		client, server := net.Pipe()
+		defer server.Close()
+		conn := &deadlineConn{Conn: client, readTimeout: time.Duration(10) * time.Millisecond, writeTimeout: time.Duration(20) * time.Millisecond}
+		defer conn.Close()
+
+		_, err := conn.Read(make([]byte, 1))
+		c.Expect(isTimeout(err), gospec.Equals, true)
+
+		timeout_err, ok := err.(*TimeoutError)
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(*timeout_err, gospec.Satisfies, "read" == timeout_err.Op && time.Duration(10)*time.Millisecond == timeout_err.After)
+	})
+
+	c.Specify("[DeadlineConn] Times out the Writes", func() {
+		client, server := net.Pipe()
+		defer server.Close()
+		conn := &deadlineConn{Conn: client, readTimeout: time.Duration(10) * time.Millisecond, writeTimeout: time.Duration(20) * time.Millisecond}
+		defer conn.Close()
+
+		_, err := conn.Write([]byte("PING"))
+		timeout_err, ok := err.(*TimeoutError)
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(*timeout_err, gospec.Satisfies, "write" == timeout_err.Op && time.Duration(20)*time.Millisecond == timeout_err.After)
+	})
+
+	c.Specify("[DeadlineConn] Cancelling the context interrupts the Reads", func() {
+		client, server := net.Pipe()
+		defer server.Close()
+		conn := &deadlineConn{Conn: client}
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := conn.watch(ctx)
+		time.AfterFunc(time.Duration(10)*time.Millisecond, cancel)
+
+		_, err := conn.Read(make([]byte, 1))
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(stop(), gospec.Equals, context.Canceled)
+
+		// Out of sync, the Writes fail too
+		_, err = conn.Write([]byte("PING"))
+		c.Expect(err, gospec.Equals, context.Canceled)
+	})
+
+	c.Specify("[DeadlineConn] The context's deadline caps the timeout", func() {
+		client, server := net.Pipe()
+		defer server.Close()
+		conn := &deadlineConn{Conn: client, readTimeout: time.Duration(10) * time.Second}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+		defer cancel()
+		stop := conn.watch(ctx)
+
+		started := time.Now()
+		_, err := conn.Read(make([]byte, 1))
+		c.Expect(isTimeout(err), gospec.Equals, true)
+
+		// Not the connection's timeout
+		_, ok := err.(*TimeoutError)
+		c.Expect(ok, gospec.Equals, false)
+		c.Expect(time.Since(started), gospec.Satisfies, time.Since(started) < time.Duration(5)*time.Second)
+		stop()
+		c.Expect(pastDeadlineErr(ctx), gospec.Equals, context.DeadlineExceeded)
+	})
+
+	c.Specify("[DeadlineConn] Stops watching the context", func() {
+		client, server := net.Pipe()
+		defer server.Close()
+		conn := &deadlineConn{Conn: client}
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := conn.watch(ctx)
+		c.Expect(stop(), gospec.Equals, nil)
+		cancel()
+
+		go server.Write([]byte("+"))
+		n, err := conn.Read(make([]byte, 1))
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(n, gospec.Equals, 1)
+	})
+}
@@ -0,0 +1,140 @@
+//
+// Consistent Hash Ring written in GO
+//
+
+package dog_pool
+
+import "crypto/md5"
+import "encoding/binary"
+import "hash/crc32"
+import "math"
+import "net"
+import "sort"
+import "strconv"
+import "strings"
+
+//
+// Points on the Ketama continuum for each server, before weighting
+//
+const ketama_points_per_server = 160
+
+//
+// Consistent hash ring, maps hashed keys to the owners of the points on the ring
+//
+type hashRing struct {
+	points []ringPoint "Points sorted by hash"
+}
+
+//
+// Point on the ring
+//
+type ringPoint struct {
+	hash  uint32
+	owner int "Index of the owner in the names the ring was made from"
+}
+
+//
+// Make a ring with replicas points for each of the names
+//
+// The points only depend on the names, so re-ordering the names
+// or adding a name only moves the keys owned by the new name.
+//
+func makeHashRing(names []string, replicas int) *hashRing {
+	p := &hashRing{points: make([]ringPoint, 0, len(names)*replicas)}
+	for owner, name := range names {
+		for i := 0; i < replicas; i++ {
+			p.points = append(p.points, ringPoint{hash: crc32.ChecksumIEEE([]byte(name + "-" + strconv.Itoa(i))), owner: owner})
+		}
+	}
+	p.sort()
+	return p
+}
+
+//
+// Make a Ketama continuum compatible with libmemcached's weighted Ketama distribution
+//
+// Each url gets its share of 160 points per server, 4 points from each MD5 digest
+// of "host:port-i", or "host-i" for the default port 11211.
+//
+func makeKetamaRing(urls []string, weights []int) *hashRing {
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+
+	p := &hashRing{}
+	for owner, url := range urls {
+		pct := float32(weights[owner]) / float32(total)
+		points := int(math.Floor(float64(pct*ketama_points_per_server/4*float32(len(urls))+0.0000000001))) * 4
+
+		host := ketamaHost(url)
+		for i := 0; i < points/4; i++ {
+			digest := md5.Sum([]byte(host + "-" + strconv.Itoa(i)))
+			for x := 0; x < 4; x++ {
+				p.points = append(p.points, ringPoint{hash: binary.LittleEndian.Uint32(digest[x*4:]), owner: owner})
+			}
+		}
+	}
+	p.sort()
+	return p
+}
+
+//
+// Hash of the key on the Ketama continuum
+//
+func ketamaHash(key string) uint32 {
+	digest := md5.Sum([]byte(key))
+	return binary.LittleEndian.Uint32(digest[0:4])
+}
+
+//
+// Name of the url on the Ketama continuum, libmemcached leaves out the default port
+//
+func ketamaHost(url string) string {
+	host, port, err := net.SplitHostPort(url)
+	if nil != err || "11211" == port {
+		return strings.TrimSuffix(url, ":11211")
+	}
+	return host + ":" + port
+}
+
+func (p *hashRing) sort() {
+	sort.SliceStable(p.points, func(i, j int) bool { return p.points[i].hash < p.points[j].hash })
+}
+
+//
+// Owner of the first point at or after the hash, wrapping around the ring
+// Returns -1 if the ring is empty
+//
+func (p *hashRing) owner(hash uint32) int {
+	if 0 == len(p.points) {
+		return -1
+	}
+
+	i := sort.Search(len(p.points), func(i int) bool { return p.points[i].hash >= hash })
+	if i == len(p.points) {
+		i = 0
+	}
+	return p.points[i].owner
+}
+
+//
+// Owner of the key, hashing only the {hashtag} if the key has one
+//
+func (p *hashRing) ownerOf(key string) int {
+	return p.owner(crc32.ChecksumIEEE([]byte(hashTag(key))))
+}
+
+//
+// Part of the key to hash, the first non-empty "{...}" in the key or the whole key
+//
+// Keys with the same hashtag, like "{user:1}:name" and "{user:1}:email", land on the same shard.
+//
+func hashTag(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key[start+1 : start+1+end]
+		}
+	}
+	return key
+}
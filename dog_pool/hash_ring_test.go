@@ -0,0 +1,108 @@
+package dog_pool
+
+import "fmt"
+import "testing"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestHashRingSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(HashRingSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func HashRingSpecs(c gospec.Context) {
+	c.Specify("[HashRing] Empty ring has no owners", func() {
+		ring := makeHashRing([]string{}, 160)
+		c.Expect(ring.ownerOf("bob"), gospec.Equals, -1)
+	})
+
+	c.Specify("[HashRing] Hashes only the hashtag", func() {
+		c.Expect(hashTag("bob"), gospec.Equals, "bob")
+		c.Expect(hashTag("{user:1}:name"), gospec.Equals, "user:1")
+		c.Expect(hashTag("name:{user:1}"), gospec.Equals, "user:1")
+		c.Expect(hashTag("{}:name"), gospec.Equals, "{}:name")
+		c.Expect(hashTag("{user:1:name"), gospec.Equals, "{user:1:name")
+		c.Expect(hashTag("{a}{b}"), gospec.Equals, "a")
+
+		ring := makeHashRing([]string{"a", "b", "c", "d"}, 160)
+		for i := 0; i < 100; i++ {
+			c.Expect(ring.ownerOf(fmt.Sprintf("{user:%d}:name", i)), gospec.Equals, ring.ownerOf(fmt.Sprintf("{user:%d}:email", i)))
+		}
+	})
+
+	c.Specify("[HashRing] Spreads the keys between the owners", func() {
+		ring := makeHashRing([]string{"a", "b", "c", "d"}, 160)
+
+		counts := make([]int, 4)
+		for i := 0; i < 10000; i++ {
+			counts[ring.ownerOf(fmt.Sprintf("key:%d", i))]++
+		}
+
+		// 2500 expected, with plenty of room for the hash
+		for _, count := range counts {
+			c.Expect(count, gospec.Satisfies, count > 1500 && count < 3500)
+		}
+	})
+
+	c.Specify("[HashRing] Adding an owner only moves keys to the new owner", func() {
+		before := makeHashRing([]string{"a", "b", "c"}, 160)
+		after := makeHashRing([]string{"a", "b", "c", "d"}, 160)
+
+		moved := 0
+		for i := 0; i < 10000; i++ {
+			key := fmt.Sprintf("key:%d", i)
+			if before.ownerOf(key) != after.ownerOf(key) {
+				moved++
+				c.Expect(after.ownerOf(key), gospec.Equals, 3)
+			}
+		}
+
+		// 2500 expected
+		c.Expect(moved, gospec.Satisfies, moved > 1500 && moved < 3500)
+	})
+
+	c.Specify("[HashRing] Ketama hashes keys with the first 4 bytes of their MD5, little-endian", func() {
+		// md5("") = d41d8cd98f00b204e9800998ecf8427e
+		c.Expect(ketamaHash(""), gospec.Equals, uint32(0xd98c1dd4))
+	})
+
+	c.Specify("[HashRing] Ketama leaves out the default port, like libmemcached", func() {
+		c.Expect(ketamaHost("10.0.0.1:11211"), gospec.Equals, "10.0.0.1")
+		c.Expect(ketamaHost("10.0.0.1:11311"), gospec.Equals, "10.0.0.1:11311")
+		c.Expect(ketamaHost("10.0.0.1"), gospec.Equals, "10.0.0.1")
+	})
+
+	c.Specify("[HashRing] Ketama gives each url 160 points, split by weight", func() {
+		counts := func(ring *hashRing) map[int]int {
+			output := map[int]int{}
+			for _, point := range ring.points {
+				output[point.owner]++
+			}
+			return output
+		}
+
+		c.Expect(counts(makeKetamaRing([]string{"a:1", "b:1", "c:1"}, []int{1, 1, 1})), gospec.Equals, map[int]int{0: 160, 1: 160, 2: 160})
+		c.Expect(counts(makeKetamaRing([]string{"a:1", "b:1"}, []int{3, 1})), gospec.Equals, map[int]int{0: 240, 1: 80})
+	})
+
+	c.Specify("[HashRing] Adding a url to Ketama only moves ~1/N of the keys to the new url", func() {
+		before := makeKetamaRing([]string{"10.0.0.1:11211", "10.0.0.2:11211", "10.0.0.3:11211"}, []int{1, 1, 1})
+		after := makeKetamaRing([]string{"10.0.0.1:11211", "10.0.0.2:11211", "10.0.0.3:11211", "10.0.0.4:11211"}, []int{1, 1, 1, 1})
+
+		moved := 0
+		for i := 0; i < 10000; i++ {
+			hash := ketamaHash(fmt.Sprintf("key:%d", i))
+			if before.owner(hash) != after.owner(hash) {
+				moved++
+				c.Expect(after.owner(hash), gospec.Equals, 3)
+			}
+		}
+
+		// 2500 expected
+		c.Expect(moved, gospec.Satisfies, moved > 1500 && moved < 3500)
+	})
+}
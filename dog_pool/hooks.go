@@ -0,0 +1,68 @@
+//
+// Lifecycle event hooks for the connections and the connection pools
+//
+
+package dog_pool
+
+//
+// Callbacks for a connection's lifecycle events, any of them may be nil
+//
+type ConnectionHooks[T any] struct {
+	OnOpen       func(T) error  "Called after the connection opens, returning an error closes it again"
+	OnClose      func(T)        "Called after an open connection is closed"
+	OnFatalError func(T, error) "Called when a fatal error closes the connection"
+}
+
+//
+// Callbacks for a connection pool's lifecycle events, any of them may be nil
+//
+// The ConnectionHooks are shared by all the connections in the pool.
+//
+type PoolHooks[T any] struct {
+	ConnectionHooks[T]
+
+	OnCreate func(T) "Called after the pool creates a connection"
+	OnBorrow func(T) "Called after a connection is Pop'd from the pool"
+	OnReturn func(T) "Called before a connection is Push'd back to the pool"
+}
+
+//
+// A nil *ConnectionHooks is valid, and doesn't call anything.
+//
+
+func (p *ConnectionHooks[T]) opened(c T) error {
+	if nil == p || nil == p.OnOpen {
+		return nil
+	}
+	return p.OnOpen(c)
+}
+
+func (p *ConnectionHooks[T]) closed(c T) {
+	if nil != p && nil != p.OnClose {
+		p.OnClose(c)
+	}
+}
+
+func (p *ConnectionHooks[T]) fatalError(c T, err error) {
+	if nil != p && nil != p.OnFatalError {
+		p.OnFatalError(c, err)
+	}
+}
+
+func (p *PoolHooks[T]) created(c T) {
+	if nil != p.OnCreate {
+		p.OnCreate(c)
+	}
+}
+
+func (p *PoolHooks[T]) borrowed(c T) {
+	if nil != p.OnBorrow {
+		p.OnBorrow(c)
+	}
+}
+
+func (p *PoolHooks[T]) returned(c T) {
+	if nil != p.OnReturn {
+		p.OnReturn(c)
+	}
+}
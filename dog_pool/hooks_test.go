@@ -0,0 +1,61 @@
+package dog_pool
+
+import "errors"
+import "testing"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestHooksSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(HooksSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func HooksSpecs(c gospec.Context) {
+	value := &stringWrapper{Value: "Hello"}
+
+	c.Specify("[ConnectionHooks] Nil hooks are ignored", func() {
+		var hooks *ConnectionHooks[*stringWrapper]
+		c.Expect(hooks.opened(value), gospec.Equals, nil)
+		hooks.closed(value)
+		hooks.fatalError(value, errors.New("Fatal Error"))
+
+		empty := &PoolHooks[*stringWrapper]{}
+		c.Expect(empty.opened(value), gospec.Equals, nil)
+		empty.created(value)
+		empty.borrowed(value)
+		empty.returned(value)
+	})
+
+	c.Specify("[ConnectionHooks] Calls the hooks with the connection", func() {
+		expected := errors.New("Fatal Error")
+		var calls []string
+
+		hooks := &PoolHooks[*stringWrapper]{
+			ConnectionHooks: ConnectionHooks[*stringWrapper]{
+				OnOpen:  func(*stringWrapper) error { calls = append(calls, "open"); return expected },
+				OnClose: func(*stringWrapper) { calls = append(calls, "close") },
+				OnFatalError: func(_ *stringWrapper, err error) {
+					c.Expect(err, gospec.Equals, expected)
+					calls = append(calls, "fatal")
+				},
+			},
+			OnCreate: func(*stringWrapper) { calls = append(calls, "create") },
+			OnBorrow: func(*stringWrapper) { calls = append(calls, "borrow") },
+			OnReturn: func(*stringWrapper) { calls = append(calls, "return") },
+		}
+
+		hooks.created(value)
+		c.Expect(hooks.opened(value), gospec.Equals, expected)
+		hooks.borrowed(value)
+		hooks.fatalError(value, expected)
+		hooks.closed(value)
+		hooks.returned(value)
+
+		c.Expect(len(calls), gospec.Equals, 6)
+		c.Expect(calls, gospec.Satisfies, "create" == calls[0] && "open" == calls[1] && "borrow" == calls[2] && "fatal" == calls[3] && "close" == calls[4] && "return" == calls[5])
+	})
+}
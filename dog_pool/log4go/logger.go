@@ -0,0 +1,53 @@
+//
+// log4go adapter for the dog_pool Logger written in GO
+//
+// Lives in its own package, so only the users of log4go depend on it.
+//
+
+package log4go
+
+import l4g "github.com/alecthomas/log4go"
+import "github.com/gnagel/dog_pool/dog_pool"
+
+//
+// Log to a log4go Logger
+//
+func MakeLogger(logger l4g.Logger) dog_pool.Logger {
+	return &log4goLogger{logger}
+}
+
+type log4goLogger struct {
+	logger l4g.Logger
+}
+
+func (p *log4goLogger) Enabled(level dog_pool.LogLevel) bool {
+	for _, filter := range p.logger {
+		if log4goLevel(level) >= filter.Level {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *log4goLogger) Logf(level dog_pool.LogLevel, format string, args ...interface{}) {
+	p.logger.Logf(log4goLevel(level), format, args...)
+}
+
+func log4goLevel(level dog_pool.LogLevel) l4g.Level {
+	switch level {
+	case dog_pool.LOG_FINEST:
+		return l4g.FINEST
+	case dog_pool.LOG_DEBUG:
+		return l4g.DEBUG
+	case dog_pool.LOG_TRACE:
+		return l4g.TRACE
+	case dog_pool.LOG_INFO:
+		return l4g.INFO
+	case dog_pool.LOG_WARNING:
+		return l4g.WARNING
+	case dog_pool.LOG_ERROR:
+		return l4g.ERROR
+	default:
+		return l4g.CRITICAL
+	}
+}
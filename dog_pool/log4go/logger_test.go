@@ -0,0 +1,36 @@
+package log4go
+
+import "testing"
+import "github.com/orfjackal/gospec/src/gospec"
+import l4g "github.com/alecthomas/log4go"
+import "github.com/gnagel/dog_pool/dog_pool"
+
+func TestLoggerSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(LoggerSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func LoggerSpecs(c gospec.Context) {
+	c.Specify("[log4go] Logs the levels the filters let through", func() {
+		logger := MakeLogger(l4g.NewDefaultLogger(l4g.WARNING))
+		c.Expect(logger.Enabled(dog_pool.LOG_INFO), gospec.Equals, false)
+		c.Expect(logger.Enabled(dog_pool.LOG_WARNING), gospec.Equals, true)
+		c.Expect(logger.Enabled(dog_pool.LOG_CRITICAL), gospec.Equals, true)
+
+		// No filters, nothing is logged
+		logger = MakeLogger(l4g.Logger{})
+		c.Expect(logger.Enabled(dog_pool.LOG_CRITICAL), gospec.Equals, false)
+	})
+
+	c.Specify("[log4go] Maps the levels", func() {
+		c.Expect(log4goLevel(dog_pool.LOG_FINEST), gospec.Equals, l4g.FINEST)
+		c.Expect(log4goLevel(dog_pool.LOG_TRACE), gospec.Equals, l4g.TRACE)
+		c.Expect(log4goLevel(dog_pool.LOG_ERROR), gospec.Equals, l4g.ERROR)
+		c.Expect(log4goLevel(dog_pool.LOG_CRITICAL), gospec.Equals, l4g.CRITICAL)
+	})
+}
@@ -0,0 +1,157 @@
+//
+// Pluggable Logger written in GO
+//
+
+package dog_pool
+
+import "context"
+import "fmt"
+import "log"
+import "log/slog"
+
+//
+// Logger the connections, pools and queues log to
+//
+// A nil Logger logs nothing, see NopLogger.
+// Use MakeStdLogger or MakeSlogLogger to log to log or log/slog, and the log4go subpackage to log to log4go.
+//
+type Logger interface {
+	// Is the level logged?  Checked before formatting the expensive messages
+	Enabled(level LogLevel) bool
+
+	// Log the message at the level
+	Logf(level LogLevel, format string, args ...interface{})
+}
+
+//
+// Logger that logs nothing, the default for a nil Logger
+//
+type NopLogger struct{}
+
+func (p NopLogger) Enabled(level LogLevel) bool {
+	return false
+}
+
+func (p NopLogger) Logf(level LogLevel, format string, args ...interface{}) {
+}
+
+var logLevelNames = []string{"FINEST", "DEBUG", "TRACE", "INFO", "WARNING", "ERROR", "CRITICAL"}
+
+func (p LogLevel) String() string {
+	if p < LOG_FINEST || p > LOG_CRITICAL {
+		return fmt.Sprintf("LogLevel(%d)", int(p))
+	}
+	return logLevelNames[p]
+}
+
+//  ==================================================
+//  Leveled helpers used by the connections and pools
+//  ==================================================
+
+//
+// Logger with a method per level, that falls back to NopLogger when the Logger is nil
+//
+type leveledLogger struct {
+	Logger
+}
+
+func logTo(logger Logger) leveledLogger {
+	if nil == logger {
+		return leveledLogger{NopLogger{}}
+	}
+	return leveledLogger{logger}
+}
+
+func (p leveledLogger) logf(level LogLevel, format string, args ...interface{}) {
+	if p.Enabled(level) {
+		p.Logf(level, format, args...)
+	}
+}
+
+func (p leveledLogger) Finest(format string, args ...interface{}) {
+	p.logf(LOG_FINEST, format, args...)
+}
+
+func (p leveledLogger) Trace(format string, args ...interface{}) {
+	p.logf(LOG_TRACE, format, args...)
+}
+
+func (p leveledLogger) Info(format string, args ...interface{}) {
+	p.logf(LOG_INFO, format, args...)
+}
+
+func (p leveledLogger) Warn(format string, args ...interface{}) {
+	p.logf(LOG_WARNING, format, args...)
+}
+
+func (p leveledLogger) Error(format string, args ...interface{}) {
+	p.logf(LOG_ERROR, format, args...)
+}
+
+func (p leveledLogger) Critical(format string, args ...interface{}) {
+	p.logf(LOG_CRITICAL, format, args...)
+}
+
+//  ==================================================
+//  Adapters
+//  ==================================================
+
+//
+// Log the messages at level or above to a standard library Logger, prefixed with their level
+//
+func MakeStdLogger(logger *log.Logger, level LogLevel) Logger {
+	return &stdLogger{logger, level}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+func (p *stdLogger) Enabled(level LogLevel) bool {
+	return level >= p.level
+}
+
+func (p *stdLogger) Logf(level LogLevel, format string, args ...interface{}) {
+	p.logger.Printf("[%v] %s", level, fmt.Sprintf(format, args...))
+}
+
+//
+// Log to a log/slog Logger
+//
+// FINEST is below slog's LevelDebug, TRACE is between LevelDebug and LevelInfo, and CRITICAL is above LevelError.
+//
+func MakeSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (p *slogLogger) Enabled(level LogLevel) bool {
+	return p.logger.Enabled(context.Background(), slogLevel(level))
+}
+
+func (p *slogLogger) Logf(level LogLevel, format string, args ...interface{}) {
+	p.logger.Log(context.Background(), slogLevel(level), fmt.Sprintf(format, args...))
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LOG_FINEST:
+		return slog.LevelDebug - 8
+	case LOG_DEBUG:
+		return slog.LevelDebug
+	case LOG_TRACE:
+		return slog.LevelDebug + 2
+	case LOG_INFO:
+		return slog.LevelInfo
+	case LOG_WARNING:
+		return slog.LevelWarn
+	case LOG_ERROR:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
@@ -0,0 +1,83 @@
+package dog_pool
+
+import "bytes"
+import "log"
+import "log/slog"
+import "strings"
+import "testing"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestLoggerSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(LoggerSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func LoggerSpecs(c gospec.Context) {
+	c.Specify("[Logger] Nil Loggers log nothing", func() {
+		// Wont' compile unless it implements the interface
+		var _ Logger = NopLogger{}
+
+		redis_connection := &RedisConnection{Url: "127.0.0.1:6991"}
+		c.Expect(redis_connection.IsOpen(), gospec.Equals, false)
+		reply := redis_connection.Cmd("GET", "bob")
+		c.Expect(reply.Err, gospec.Satisfies, nil != reply.Err)
+
+		memcached_connection := &MemcachedConnection{Url: "127.0.0.1:11391"}
+		c.Expect(memcached_connection.IsOpen(), gospec.Equals, false)
+		_, err := memcached_connection.Get("bob")
+		c.Expect(err, gospec.Satisfies, nil != err)
+
+		pool := &RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		err = pool.Do(func(client RedisDsl) error { return client.Cmd("PING").Err })
+		c.Expect(err, gospec.Satisfies, nil != err)
+
+		MakeStopWatch(c, nil, "Make").Start().Stop().LogDuration()
+	})
+
+	c.Specify("[Logger] Names the levels", func() {
+		c.Expect(LOG_FINEST.String(), gospec.Equals, "FINEST")
+		c.Expect(LOG_WARNING.String(), gospec.Equals, "WARNING")
+		c.Expect(LOG_CRITICAL.String(), gospec.Equals, "CRITICAL")
+		c.Expect(LogLevel(42).String(), gospec.Equals, "LogLevel(42)")
+	})
+
+	c.Specify("[Logger] Logs to the standard library's log", func() {
+		buffer := &bytes.Buffer{}
+		logger := MakeStdLogger(log.New(buffer, "", 0), LOG_WARNING)
+		c.Expect(logger.Enabled(LOG_INFO), gospec.Equals, false)
+		c.Expect(logger.Enabled(LOG_WARNING), gospec.Equals, true)
+		c.Expect(logger.Enabled(LOG_CRITICAL), gospec.Equals, true)
+
+		logTo(logger).Info("Hidden %d", 1)
+		logTo(logger).Warn("Shown %d", 2)
+		c.Expect(buffer.String(), gospec.Equals, "[WARNING] Shown 2\n")
+	})
+
+	c.Specify("[Logger] Logs to log/slog", func() {
+		buffer := &bytes.Buffer{}
+		logger := MakeSlogLogger(slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: slog.LevelInfo})))
+		c.Expect(logger.Enabled(LOG_TRACE), gospec.Equals, false)
+		c.Expect(logger.Enabled(LOG_INFO), gospec.Equals, true)
+
+		logTo(logger).Trace("Hidden %d", 1)
+		logTo(logger).Error("Shown %d", 2)
+		c.Expect(buffer.String(), gospec.Satisfies, strings.Contains(buffer.String(), `level=ERROR msg="Shown 2"`))
+		c.Expect(strings.Count(buffer.String(), "\n"), gospec.Equals, 1)
+	})
+
+	c.Specify("[Logger] Connections log through the Logger", func() {
+		buffer := &bytes.Buffer{}
+		connection := &RedisConnection{Url: "127.0.0.1:6991", Id: "Bob", Logger: MakeStdLogger(log.New(buffer, "", 0), LOG_WARNING)}
+		reply := connection.Cmd("GET", "bob")
+		c.Expect(reply.Err, gospec.Satisfies, nil != reply.Err)
+		c.Expect(buffer.String(), gospec.Satisfies, strings.HasPrefix(buffer.String(), "[ERROR] [RedisConnection][Open][127.0.0.1:6991/Bob]"))
+		c.Expect(buffer.String(), gospec.Satisfies, strings.Contains(buffer.String(), "\n[WARNING] [RedisConnection][Append][127.0.0.1:6991/Bob]"))
+	})
+}
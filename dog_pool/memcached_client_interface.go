@@ -1,7 +1,7 @@
 //
 // Memcached Client Interface
 //
-// Interface implemented by memcached.Client and dog_pool.MemcachedConnection
+// Interface implemented by memcached.Client, dog_pool.MemcachedConnection and dog_pool.MemcachedConnectionPool
 //
 
 package dog_pool
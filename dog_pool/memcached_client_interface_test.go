@@ -37,4 +37,12 @@ func MemcachedClientInterfaceSpecs(c gospec.Context) {
 		var memcached_interface MemcachedClientInterface = client
 		c.Expect(memcached_interface, gospec.Satisfies, true)
 	})
+
+	c.Specify("[MemcachedClientInterface] MemcachedConnectionPool satisfies MemcachedClientInterface", func() {
+		pool := &MemcachedConnectionPool{}
+
+		// Wont' compile unless it implements the interface
+		var memcached_interface MemcachedClientInterface = pool
+		c.Expect(memcached_interface, gospec.Satisfies, true)
+	})
 }
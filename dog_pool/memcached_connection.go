@@ -5,12 +5,14 @@
 package dog_pool
 
 import "bytes"
+import "context"
+import "crypto/tls"
+import "errors"
 import "fmt"
-import "runtime"
+import "net"
 import "strconv"
 import "strings"
 import "time"
-import "github.com/alecthomas/log4go"
 import memcached "github.com/bradfitz/gomemcache/memcache"
 
 //
@@ -21,19 +23,40 @@ type MemcachedConnection struct {
 
 	Id string "(optional) Identifier for distingushing between memcached connections"
 
-	Logger *log4go.Logger "Handle to the logger we are using"
+	Logger Logger "(optional) Handle to the logger we are using, nil logs nothing"
 
-	Timeout time.Duration "Timeout"
+	Timeout time.Duration "Timeout, for dialing, reading and writing unless they have their own timeouts"
+
+	DialTimeout  time.Duration "(optional) Timeout for dialing Memcached, defaults to Timeout"
+	ReadTimeout  time.Duration "(optional) Timeout for reading each reply, defaults to Timeout"
+	WriteTimeout time.Duration "(optional) Timeout for writing each command, defaults to Timeout"
+
+	Hooks *ConnectionHooks[*MemcachedConnection] "(optional) Callbacks for the connection's lifecycle events"
+
+	TLSConfig *tls.Config "(optional) Dial with TLS, for memcached servers started with -Z"
+
+	Retry *RetryPolicy "(optional) Retry the Get's and GetMulti's that fail on network errors, nil disables the retries"
 
 	client *memcached.Client "Connection to a Memcached, may be nil"
+
+	stats *connectionStats "(optional) Counters shared with the pool, may be nil"
+
+	breaker *circuitBreaker "(optional) Circuit breaker shared by the connections to Url, may be nil"
+}
+
+//
+// Logger to log to, NopLogger when Logger is nil
+//
+func (p *MemcachedConnection) logger() leveledLogger {
+	return logTo(p.Logger)
 }
 
 //
 // Lazily make a Redis Connection
 //
-func makeLazyMemcachedConnection(url string, id string, timeout time.Duration, logger *log4go.Logger) (*MemcachedConnection, error) {
+func makeLazyMemcachedConnection(url string, id string, timeouts connectionTimeouts, tls_config *tls.Config, logger Logger, stats *connectionStats, hooks *ConnectionHooks[*MemcachedConnection], breaker *circuitBreaker) (*MemcachedConnection, error) {
 	// Create a new factory instance
-	p := &MemcachedConnection{Url: url, Id: id, Logger: logger, Timeout: timeout}
+	p := &MemcachedConnection{Url: url, Id: id, Logger: logger, Timeout: timeouts.Timeout, DialTimeout: timeouts.Dial, ReadTimeout: timeouts.Read, WriteTimeout: timeouts.Write, TLSConfig: tls_config, Hooks: hooks, stats: stats, breaker: breaker}
 
 	// Return the factory
 	return p, nil
@@ -42,9 +65,9 @@ func makeLazyMemcachedConnection(url string, id string, timeout time.Duration, l
 //
 // Agressively make a Memcached Connection
 //
-func makeAgressiveMemcachedConnection(url string, id string, timeout time.Duration, logger *log4go.Logger) (*MemcachedConnection, error) {
+func makeAgressiveMemcachedConnection(url string, id string, timeouts connectionTimeouts, tls_config *tls.Config, logger Logger, stats *connectionStats, hooks *ConnectionHooks[*MemcachedConnection], breaker *circuitBreaker) (*MemcachedConnection, error) {
 	// Create a new factory instance
-	p, _ := makeLazyMemcachedConnection(url, id, timeout, logger)
+	p, _ := makeLazyMemcachedConnection(url, id, timeouts, tls_config, logger, stats, hooks, breaker)
 
 	// Ping the server
 	if err := p.Ping(); nil != err {
@@ -67,18 +90,19 @@ func (p *MemcachedConnection) recoverPanic(cmd string, keys []string) error {
 	}
 
 	// Panic error
-	p.Logger.Critical("[MemcachedConnection][%s][%s/%s] Memcached Keys = '%s' --> Panic Error = '%v'", cmd, p.Url, p.Id, strings.Join(keys, ", "), r)
+	p.logger().Critical("[MemcachedConnection][%s][%s/%s] Memcached Keys = '%s' --> Panic Error = '%v'", cmd, p.Url, p.Id, strings.Join(keys, ", "), r)
+
+	// Cast the error
+	err := panicError(r)
 
 	// Close the connection
+	p.stats.addFatalError()
+	p.breaker.failure()
+	p.Hooks.fatalError(p, err)
 	p.Close()
 
-	// Cast the error
-	if e, ok := r.(runtime.Error); ok {
-		return e
-	}
-
 	// Return the error
-	return r.(error)
+	return err
 }
 
 func (p *MemcachedConnection) checkIsOpen(cmd string, keys []string) error {
@@ -89,7 +113,7 @@ func (p *MemcachedConnection) checkIsOpen(cmd string, keys []string) error {
 	// Did opening the connection fail?
 	err := p.Open()
 	if nil != err {
-		p.Logger.Warn("[MemcachedConnection][%s][%s/%s] Memcached Keys = '%s' --> Open Error = '%v'", cmd, p.Url, p.Id, strings.Join(keys, ","), err)
+		p.logger().Warn("[MemcachedConnection][%s][%s/%s] Memcached Keys = '%s' --> Open Error = '%v'", cmd, p.Url, p.Id, strings.Join(keys, ","), err)
 	}
 
 	// Return the error, may be nil
@@ -113,6 +137,15 @@ func toStringSlice(item *memcached.Item) []string {
 // cache misses. Each key must be at most 250 bytes in length.
 // If no error is returned, the returned map will also be non-nil.
 func (p *MemcachedConnection) GetMulti(keys []string) (output map[string]*memcached.Item, err error) {
+	err = p.Retry.do(context.Background(), func(attempt int) error {
+		p.logRetry("GetMulti", keys, attempt, err)
+		output, err = p.getMulti(keys)
+		return err
+	})
+	return
+}
+
+func (p *MemcachedConnection) getMulti(keys []string) (output map[string]*memcached.Item, err error) {
 	// Recover from panic'd errors
 	defer func() {
 		if recovered_err := p.recoverPanic("GetMulti", keys); nil != recovered_err {
@@ -130,11 +163,12 @@ func (p *MemcachedConnection) GetMulti(keys []string) (output map[string]*memcac
 	// Perform the memcached request
 	stop_watch := MakeStopWatchTags(p, p.Logger, append([]string{p.Url, p.Id, "GetMulti"}, keys...)).Start()
 	output, err = p.client.GetMulti(keys)
-	stop_watch.Stop().LogDurationAt(log4go.TRACE)
+	stop_watch.Stop().LogDurationAt(LOG_TRACE)
 
 	switch err {
 	case nil:
-		p.Logger.Trace(func() string {
+		p.breaker.success()
+		if p.logger().Enabled(LOG_TRACE) {
 			buffer := make([]string, len(output))
 			i := 0
 			for key, item := range output {
@@ -142,10 +176,13 @@ func (p *MemcachedConnection) GetMulti(keys []string) (output map[string]*memcac
 				i++
 			}
 
-			return fmt.Sprintf("[MemcachedConnection][Get][%s/%s] Keys = '%v' --> Got Values = [%s]!", p.Url, p.Id, strings.Join(keys, ","), strings.Join(buffer, ", "))
-		})
+			p.logger().Trace("[MemcachedConnection][Get][%s/%s] Keys = '%v' --> Got Values = [%s]!", p.Url, p.Id, strings.Join(keys, ","), strings.Join(buffer, ", "))
+		}
 	default:
-		p.Logger.Error("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Fatal Error = '%v'", p.Url, p.Id, strings.Join(keys, ","), err)
+		p.logger().Error("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Fatal Error = '%v'", p.Url, p.Id, strings.Join(keys, ","), err)
+		p.stats.addFatalError()
+		p.breaker.failure()
+		p.Hooks.fatalError(p, err)
 		p.Close()
 	}
 
@@ -155,6 +192,15 @@ func (p *MemcachedConnection) GetMulti(keys []string) (output map[string]*memcac
 // Get gets the item for the given key. ErrCacheMiss is returned for a
 // memcache cache miss. The key must be at most 250 bytes in length.
 func (p *MemcachedConnection) Get(key string) (item *memcached.Item, err error) {
+	err = p.Retry.do(context.Background(), func(attempt int) error {
+		p.logRetry("Get", []string{key}, attempt, err)
+		item, err = p.get(key)
+		return err
+	})
+	return
+}
+
+func (p *MemcachedConnection) get(key string) (item *memcached.Item, err error) {
 	// Recover from panic'd errors
 	defer func() {
 		if recovered_err := p.recoverPanic("Get", []string{key}); nil != recovered_err {
@@ -171,15 +217,19 @@ func (p *MemcachedConnection) Get(key string) (item *memcached.Item, err error)
 	// Perform the memcached request
 	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "Get", key}).Start()
 	item, err = p.client.Get(key)
-	stop_watch.Stop().LogDurationAt(log4go.TRACE)
+	stop_watch.Stop().LogDurationAt(LOG_TRACE)
 
 	switch err {
 	case nil:
-		p.Logger.Trace("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Got Value = '%v'!", p.Url, p.Id, key, bytes.NewBuffer(item.Value).String())
+		p.breaker.success()
+		p.logger().Trace("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Got Value = '%v'!", p.Url, p.Id, key, bytes.NewBuffer(item.Value).String())
 	case memcached.ErrCacheMiss:
-		p.Logger.Trace("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Not Stored = '%v'", p.Url, p.Id, key, err)
+		p.logger().Trace("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Not Stored = '%v'", p.Url, p.Id, key, err)
 	default:
-		p.Logger.Error("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Fatal Error = '%v'", p.Url, p.Id, key, err)
+		p.logger().Error("[MemcachedConnection][Get][%s/%s] Key = '%v' --> Fatal Error = '%v'", p.Url, p.Id, key, err)
+		p.stats.addFatalError()
+		p.breaker.failure()
+		p.Hooks.fatalError(p, err)
 		p.Close()
 	}
 
@@ -206,15 +256,19 @@ func (p *MemcachedConnection) Set(item *memcached.Item) (err error) {
 	// Perform the memcached request
 	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "Set", item.Key}).Start()
 	err = p.client.Set(item)
-	stop_watch.Stop().LogDurationAt(log4go.TRACE)
+	stop_watch.Stop().LogDurationAt(LOG_TRACE)
 
 	key := item.Key
 	delta := bytes.NewBuffer(item.Value).String()
 	switch err {
 	case nil:
-		p.Logger.Trace("[MemcachedConnection][Set][%s/%s] Key = '%v', Value = '%v', Expires = %d(s) --> Set Value!", p.Url, p.Id, key, delta, item.Expiration)
+		p.breaker.success()
+		p.logger().Trace("[MemcachedConnection][Set][%s/%s] Key = '%v', Value = '%v', Expires = %d(s) --> Set Value!", p.Url, p.Id, key, delta, item.Expiration)
 	default:
-		p.Logger.Error("[MemcachedConnection][Set][%s/%s] Key = '%v', Value = '%v', Expires = %d(s) --> Fatal Error = '%v'", p.Url, p.Id, key, delta, item.Expiration, err)
+		p.logger().Error("[MemcachedConnection][Set][%s/%s] Key = '%v', Value = '%v', Expires = %d(s) --> Fatal Error = '%v'", p.Url, p.Id, key, delta, item.Expiration, err)
+		p.stats.addFatalError()
+		p.breaker.failure()
+		p.Hooks.fatalError(p, err)
 		p.Close()
 	}
 
@@ -240,15 +294,19 @@ func (p *MemcachedConnection) Delete(key string) (err error) {
 	// Perform the memcached request
 	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "Delete", key}).Start()
 	err = p.client.Delete(key)
-	stop_watch.Stop().LogDurationAt(log4go.TRACE)
+	stop_watch.Stop().LogDurationAt(LOG_TRACE)
 
 	switch err {
 	case nil:
-		p.Logger.Trace("[MemcachedConnection][Delete][%s/%s] Key = '%v' --> Deleted Value!", p.Url, p.Id, key)
+		p.breaker.success()
+		p.logger().Trace("[MemcachedConnection][Delete][%s/%s] Key = '%v' --> Deleted Value!", p.Url, p.Id, key)
 	case memcached.ErrCacheMiss:
-		p.Logger.Trace("[MemcachedConnection][Delete][%s/%s] Key = '%v' --> Not Stored = '%v'", p.Url, p.Id, key, err)
+		p.logger().Trace("[MemcachedConnection][Delete][%s/%s] Key = '%v' --> Not Stored = '%v'", p.Url, p.Id, key, err)
 	default:
-		p.Logger.Error("[MemcachedConnection][Delete][%s/%s] Key = '%v' --> Fatal Error = '%v'", p.Url, p.Id, key, err)
+		p.logger().Error("[MemcachedConnection][Delete][%s/%s] Key = '%v' --> Fatal Error = '%v'", p.Url, p.Id, key, err)
+		p.stats.addFatalError()
+		p.breaker.failure()
+		p.Hooks.fatalError(p, err)
 		p.Close()
 	}
 
@@ -276,17 +334,21 @@ func (p *MemcachedConnection) Add(item *memcached.Item) (err error) {
 	// Perform the memcached request
 	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "Add", item.Key}).Start()
 	err = p.client.Add(item)
-	stop_watch.Stop().LogDurationAt(log4go.TRACE)
+	stop_watch.Stop().LogDurationAt(LOG_TRACE)
 
 	key := item.Key
 	delta := bytes.NewBuffer(item.Value).String()
 	switch err {
 	case nil:
-		p.Logger.Trace("[MemcachedConnection][Add][%s/%s] Key = '%v', Value = '%v' --> Added Value!", p.Url, p.Id, key, delta)
+		p.breaker.success()
+		p.logger().Trace("[MemcachedConnection][Add][%s/%s] Key = '%v', Value = '%v' --> Added Value!", p.Url, p.Id, key, delta)
 	case memcached.ErrNotStored:
-		p.Logger.Trace("[MemcachedConnection][Add][%s/%s] Key = '%v', Value = '%v' --> Not Stored = '%v'", p.Url, p.Id, key, delta, err)
+		p.logger().Trace("[MemcachedConnection][Add][%s/%s] Key = '%v', Value = '%v' --> Not Stored = '%v'", p.Url, p.Id, key, delta, err)
 	default:
-		p.Logger.Error("[MemcachedConnection][Add][%s/%s] Key = '%v', Value = '%v' --> Fatal Error = '%v'", p.Url, p.Id, key, delta, err)
+		p.logger().Error("[MemcachedConnection][Add][%s/%s] Key = '%v', Value = '%v' --> Fatal Error = '%v'", p.Url, p.Id, key, delta, err)
+		p.stats.addFatalError()
+		p.breaker.failure()
+		p.Hooks.fatalError(p, err)
 		p.Close()
 	}
 
@@ -317,15 +379,19 @@ func (p *MemcachedConnection) Increment(key string, delta uint64) (newValue uint
 	// Perform the memcached request
 	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "Increment", key}).Start()
 	newValue, err = p.client.Increment(key, delta)
-	stop_watch.Stop().LogDurationAt(log4go.TRACE)
+	stop_watch.Stop().LogDurationAt(LOG_TRACE)
 
 	switch err {
 	case nil:
-		p.Logger.Trace("[MemcachedConnection][Increment][%s/%s] Key = '%v', Delta = %d --> Incremented Value = %d!", p.Url, p.Id, key, delta, newValue)
+		p.breaker.success()
+		p.logger().Trace("[MemcachedConnection][Increment][%s/%s] Key = '%v', Delta = %d --> Incremented Value = %d!", p.Url, p.Id, key, delta, newValue)
 	case memcached.ErrCacheMiss:
-		p.Logger.Trace("[MemcachedConnection][Increment][%s/%s] Key = '%v', Delta = %d --> Not Stored = '%v'", p.Url, p.Id, key, delta, err)
+		p.logger().Trace("[MemcachedConnection][Increment][%s/%s] Key = '%v', Delta = %d --> Not Stored = '%v'", p.Url, p.Id, key, delta, err)
 	default:
-		p.Logger.Error("[MemcachedConnection][Increment][%s/%s] Key = '%v', Delta = %d --> Fatal Error = '%v'", p.Url, p.Id, key, err)
+		p.logger().Error("[MemcachedConnection][Increment][%s/%s] Key = '%v', Delta = %d --> Fatal Error = '%v'", p.Url, p.Id, key, delta, err)
+		p.stats.addFatalError()
+		p.breaker.failure()
+		p.Hooks.fatalError(p, err)
 		p.Close()
 	}
 
@@ -357,15 +423,19 @@ func (p *MemcachedConnection) Decrement(key string, delta uint64) (newValue uint
 	// Perform the memcached request
 	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "Decrement", key}).Start()
 	newValue, err = p.client.Decrement(key, delta)
-	stop_watch.Stop().LogDurationAt(log4go.TRACE)
+	stop_watch.Stop().LogDurationAt(LOG_TRACE)
 
 	switch err {
 	case nil:
-		p.Logger.Trace("[MemcachedConnection][Decrement][%s/%s] Key = '%v', Delta = %d --> Decremented Value = %d!", p.Url, p.Id, key, delta, newValue)
+		p.breaker.success()
+		p.logger().Trace("[MemcachedConnection][Decrement][%s/%s] Key = '%v', Delta = %d --> Decremented Value = %d!", p.Url, p.Id, key, delta, newValue)
 	case memcached.ErrCacheMiss:
-		p.Logger.Trace("[MemcachedConnection][Decrement][%s/%s] Key = '%v', Delta = %d --> Not Stored = '%v'", p.Url, p.Id, key, delta, err)
+		p.logger().Trace("[MemcachedConnection][Decrement][%s/%s] Key = '%v', Delta = %d --> Not Stored = '%v'", p.Url, p.Id, key, delta, err)
 	default:
-		p.Logger.Error("[MemcachedConnection][Decrement][%s/%s] Key = '%v', Delta = %d --> Fatal Error = '%v'", p.Url, p.Id, key, err)
+		p.logger().Error("[MemcachedConnection][Decrement][%s/%s] Key = '%v', Delta = %d --> Fatal Error = '%v'", p.Url, p.Id, key, delta, err)
+		p.stats.addFatalError()
+		p.breaker.failure()
+		p.Hooks.fatalError(p, err)
 		p.Close()
 	}
 
@@ -383,11 +453,17 @@ func (p *MemcachedConnection) Decrement(key string, delta uint64) (newValue uint
 // Create a new (un-opened) copy of this MemcachedConnection
 func (p *MemcachedConnection) Clone() *MemcachedConnection {
 	return &MemcachedConnection{
-		Url:     p.Url,
-		Id:      p.Id,
-		Logger:  p.Logger,
-		Timeout: p.Timeout,
-		client:  nil,
+		Url:          p.Url,
+		Id:           p.Id,
+		Logger:       p.Logger,
+		Timeout:      p.Timeout,
+		DialTimeout:  p.DialTimeout,
+		ReadTimeout:  p.ReadTimeout,
+		WriteTimeout: p.WriteTimeout,
+		TLSConfig:    p.TLSConfig,
+		Hooks:        p.Hooks,
+		Retry:        p.Retry,
+		client:       nil,
 	}
 }
 
@@ -444,7 +520,7 @@ func (p *MemcachedConnection) IsOpen() bool {
 	output := nil != p.client
 
 	// Debug logging
-	p.Logger.Trace("[MemcachedConnection][IsOpen][%s/%s] --> %v", p.Url, p.Id, output)
+	p.logger().Trace("[MemcachedConnection][IsOpen][%s/%s] --> %v", p.Url, p.Id, output)
 
 	return output
 }
@@ -456,7 +532,7 @@ func (p *MemcachedConnection) IsClosed() bool {
 	output := nil == p.client
 
 	// Debug logging
-	p.Logger.Trace("[MemcachedConnection][IsClosed][%s/%s] --> %v", p.Url, p.Id, output)
+	p.logger().Trace("[MemcachedConnection][IsClosed][%s/%s] --> %v", p.Url, p.Id, output)
 
 	return output
 }
@@ -465,13 +541,27 @@ func (p *MemcachedConnection) IsClosed() bool {
 // Open a new connection to memcached
 //
 func (p *MemcachedConnection) Open() error {
-	// Open the TCP connection -and-
+	// Set the default timeout
+	if time.Duration(0) == p.Timeout {
+		p.Timeout = default_timeout
+	}
+
+	// Fail fast while the circuit breaker is open,
+	// the Set/Delete below doubles as the probe
+	if ok, _ := p.breaker.allow(); !ok {
+		p.logger().Warn("[MemcachedConnection][Open][%s/%s] --> Error = '%v'", p.Url, p.Id, ErrCircuitOpen)
+		return ErrCircuitOpen
+	}
+
+	// Open the TCP, TLS or Unix socket connection -and-
 	// Save the client pointer
-	p.client = memcached.New(p.Url)
-	p.client.Timeout = time.Duration(10) * time.Second
+	timeouts := p.timeouts()
+	p.client = memcached.New(memcachedAddr(p.Url))
+	p.client.Timeout = timeouts.dial()
+	p.client.DialContext = p.dial(timeouts)
 
 	// Log the event
-	p.Logger.Info("[MemcachedConnection][Open][%s/%s] --> Opened!", p.Url, p.Id)
+	p.logger().Info("[MemcachedConnection][Open][%s/%s] --> Opened!", p.Url, p.Id)
 
 	// Perform a basic command on the server
 	item := &memcached.Item{}
@@ -480,23 +570,44 @@ func (p *MemcachedConnection) Open() error {
 	item.Expiration = int32(10) // Seconds
 
 	// Set, then delete the item
+	// Count a failure as a dial error, not as a fatal error
+	stats, hooks, breaker := p.stats, p.Hooks, p.breaker
+	p.stats, p.Hooks, p.breaker = nil, nil, nil
 	err := p.Set(item)
 	if nil == err {
 		p.Delete(item.Key)
 	}
+	p.stats, p.Hooks, p.breaker = stats, hooks, breaker
 
 	// Check for errors
 	if nil != err {
 		// Reset the pointer to nil
 		p.client = nil
 
+		// memcached replaces the dial's timeout error with its own
+		var connect_err *memcached.ConnectTimeoutError
+		if errors.As(err, &connect_err) {
+			err = &TimeoutError{Op: "dial", After: timeouts.dial(), Err: err}
+		}
+
 		// Log the event
-		p.Logger.Error("[MemcachedConnection][Open][%s/%s] --> Error = '%v'", p.Url, p.Id, err)
+		p.logger().Error("[MemcachedConnection][Open][%s/%s] --> Error = '%v'", p.Url, p.Id, err)
+		p.stats.addDialError()
+		p.breaker.failure()
 
 		// Return the error
 		return err
 	}
 
+	p.breaker.success()
+
+	// Let the hooks prepare the connection, closing it on errors
+	if err := p.Hooks.opened(p); nil != err {
+		p.logger().Error("[MemcachedConnection][Open][%s/%s] --> OnOpen Error = '%v'", p.Url, p.Id, err)
+		p.Close()
+		return err
+	}
+
 	// Return nil
 	return nil
 }
@@ -506,10 +617,56 @@ func (p *MemcachedConnection) Open() error {
 //
 func (p *MemcachedConnection) Close() (err error) {
 	// Set the pointer to nil
+	was_open := nil != p.client
 	p.client = nil
 
 	// Log the event
-	p.Logger.Info("[MemcachedConnection][Close][%s/%s] --> Closed!", p.Url, p.Id)
+	p.logger().Info("[MemcachedConnection][Close][%s/%s] --> Closed!", p.Url, p.Id)
 
+	if was_open {
+		p.Hooks.closed(p)
+	}
 	return
 }
+
+func (p *MemcachedConnection) logRetry(cmd string, keys []string, attempt int, err error) {
+	if attempt > 1 {
+		p.logger().Warn("[MemcachedConnection][%s][%s/%s] Key = '%v' --> Retrying, attempt=%d, Error = '%v'", cmd, p.Url, p.Id, strings.Join(keys, ","), attempt, err)
+	}
+}
+
+//
+// Timeouts of the connection, the Dial, Read and Write timeouts fall back to Timeout
+//
+func (p *MemcachedConnection) timeouts() connectionTimeouts {
+	return connectionTimeouts{Timeout: p.Timeout, Dial: p.DialTimeout, Read: p.ReadTimeout, Write: p.WriteTimeout}
+}
+
+//
+// Dial with TLS when TLSConfig is set, and apply the read/write timeouts on the socket
+//
+func (p *MemcachedConnection) dial(timeouts connectionTimeouts) func(ctx context.Context, network, address string) (net.Conn, error) {
+	tls_config := p.TLSConfig
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: timeouts.dial()}
+
+		var conn net.Conn
+		var err error
+		if nil == tls_config {
+			conn, err = dialer.DialContext(ctx, network, address)
+		} else {
+			conn, err = (&tls.Dialer{NetDialer: dialer, Config: tls_config}).DialContext(ctx, network, address)
+		}
+		if nil != err {
+			return nil, err
+		}
+		return &deadlineConn{Conn: conn, readTimeout: timeouts.read(), writeTimeout: timeouts.write()}, nil
+	}
+}
+
+//
+// Address memcached.New dials: the socket's path for unix:// urls, the url itself otherwise
+//
+func memcachedAddr(url string) string {
+	return strings.TrimPrefix(url, "unix://")
+}
@@ -1,8 +1,11 @@
 package dog_pool
 
+import "fmt"
+import "os"
+import "path/filepath"
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
-import "github.com/alecthomas/log4go"
 import memcached "github.com/bradfitz/gomemcache/memcache"
 
 func TestMemcachedConnectionSpecs(t *testing.T) {
@@ -17,10 +20,10 @@ func TestMemcachedConnectionSpecs(t *testing.T) {
 
 // Helpers
 func MemcachedConnectionSpecs(c gospec.Context) {
-	var memcached_connection_logger = log4go.NewDefaultLogger(log4go.CRITICAL)
+	var memcached_connection_logger = MakeStdLogger(log.Default(), LOG_CRITICAL)
 
 	c.Specify("[MemcachedConnection] New connection is not open", func() {
-		connection := MemcachedConnection{Url: "127.0.0.1:11290", Logger: &memcached_connection_logger}
+		connection := MemcachedConnection{Url: "127.0.0.1:11290", Logger: memcached_connection_logger}
 		defer connection.Close()
 
 		// Should be opposite of each other:
@@ -29,7 +32,7 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnection] Opening connection to Invalid Host/Port has errors", func() {
-		connection := MemcachedConnection{Url: "127.0.0.1:11291", Logger: &memcached_connection_logger}
+		connection := MemcachedConnection{Url: "127.0.0.1:11291", Logger: memcached_connection_logger}
 		defer connection.Close()
 
 		c.Expect(nil != connection.Open(), gospec.Equals, true)
@@ -37,8 +40,8 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnection] Opening connection to Valid Host/Port has no errors", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartMemcachedServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -50,8 +53,8 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnection] Ping (-->Set-->Delete) (re-)opens the connection automatically", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartMemcachedServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -77,8 +80,8 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnection] Clone+Ping (-->Set-->Delete) (re-)opens the connection automatically", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartMemcachedServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -107,8 +110,8 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnection][Get] Returns Cache Miss", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartMemcachedServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -120,8 +123,8 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnection][Set+Get] Returns Value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartMemcachedServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -137,8 +140,8 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnection][SetStr+GetStr] Returns Value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartMemcachedServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -152,11 +155,131 @@ func MemcachedConnectionSpecs(c gospec.Context) {
 		c.Expect(*ptr, gospec.Equals, "Hello")
 	})
 
+	c.Specify("[MemcachedConnection] Hooks are called when the connection opens and closes", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		opened, closed := 0, 0
+		connection := &MemcachedConnection{Url: server.Url(), Logger: logger, Hooks: &ConnectionHooks[*MemcachedConnection]{
+			OnOpen:  func(*MemcachedConnection) error { opened++; return nil },
+			OnClose: func(*MemcachedConnection) { closed++ },
+		}}
+
+		c.Expect(connection.Open(), gospec.Equals, nil)
+		c.Expect(opened, gospec.Equals, 1)
+
+		connection.Close()
+		connection.Close()
+		c.Expect(closed, gospec.Equals, 1)
+	})
+
+	c.Specify("[MemcachedConnection] OnOpen errors close the connection", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		expected := fmt.Errorf("OnOpen Error")
+		connection := &MemcachedConnection{Url: server.Url(), Logger: logger, Hooks: &ConnectionHooks[*MemcachedConnection]{
+			OnOpen: func(*MemcachedConnection) error { return expected },
+		}}
+
+		c.Expect(connection.Open(), gospec.Equals, expected)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnection] OnFatalError is called when a fatal error closes the connection", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartMemcachedServer(logger)
+		if nil != err {
+			panic(err)
+		}
+
+		var fatal error
+		connection := &MemcachedConnection{Url: server.Url(), Logger: logger, Hooks: &ConnectionHooks[*MemcachedConnection]{
+			OnFatalError: func(_ *MemcachedConnection, err error) { fatal = err },
+		}}
+		c.Expect(connection.Open(), gospec.Equals, nil)
+
+		// Stop the server under the open connection
+		server.Close()
+		connection.Ping()
+		c.Expect(fatal, gospec.Satisfies, nil != fatal)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnection] Connects with TLS", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		dir, err := os.MkdirTemp("", "dog_pool")
+		if nil != err {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		cert, err := MakeSelfSignedCert(dir)
+		if nil != err {
+			panic(err)
+		}
+		server, err := startMemcachedServer(logger, func(port int) ([]string, error) {
+			return []string{"-p", fmt.Sprintf("%d", port), "-Z", "-o", fmt.Sprintf("ssl_chain_cert=%s,ssl_key=%s", cert.CertFile, cert.KeyFile)}, nil
+		})
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		connection := &MemcachedConnection{Url: server.Url(), Logger: logger, TLSConfig: cert.ClientConfig}
+		defer connection.Close()
+		c.Expect(connection.SetStr("bob", "hello", 10), gospec.Equals, nil)
+
+		value, err := connection.GetStr("bob")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value, gospec.Equals, "hello")
+
+		// Without TLS
+		plain := &MemcachedConnection{Url: server.Url(), Logger: logger}
+		defer plain.Close()
+		err = plain.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+	})
+
+	c.Specify("[MemcachedConnection] Connects to a unix:// socket", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		dir, err := os.MkdirTemp("", "dog_pool")
+		if nil != err {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		socket := filepath.Join(dir, "memcached.sock")
+		server, err := startMemcachedServer(logger, func(port int) ([]string, error) {
+			return []string{"-s", socket}, nil
+		})
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		connection := &MemcachedConnection{Url: "unix://" + socket, Logger: logger}
+		defer connection.Close()
+		c.Expect(connection.SetStr("bob", "hello", 10), gospec.Equals, nil)
+
+		value, err := connection.GetStr("bob")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value, gospec.Equals, "hello")
+	})
+
 }
 
 func Benchmark_MemcachedConnection_Get(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartMemcachedServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartMemcachedServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -171,8 +294,8 @@ func Benchmark_MemcachedConnection_Get(b *testing.B) {
 }
 
 func Benchmark_MemcachedConnection_Set(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartMemcachedServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartMemcachedServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -185,8 +308,8 @@ func Benchmark_MemcachedConnection_Set(b *testing.B) {
 }
 
 func Benchmark_MemcachedConnection_SetGet(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartMemcachedServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartMemcachedServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -4,35 +4,80 @@
 
 package dog_pool
 
+import "context"
+import "crypto/tls"
 import "fmt"
 import "errors"
+import "sync"
 import "time"
-import "github.com/alecthomas/log4go"
+import memcached "github.com/bradfitz/gomemcache/memcache"
 
 //
 // Memcached Connection Pool wrapper
 //
+// Pop round-robins between the Urls, PopKey and the MemcachedClientInterface methods
+// route each key to the Url that owns it on a Ketama continuum compatible with libmemcached.
+// The Url weights are the Ketama weights, so adding a Url only moves ~1/N of the keys.
+//
 type MemcachedConnectionPool struct {
-	Mode    ConnectionMode         "How should we prepare the connection pool?"
-	Size    int                    "(Max) Pool size"
-	Urls    []string               "Memcached URLs to connect to"
-	Logger  log4go.Logger          "Logger we are using in the connection pool"
-	Timeout time.Duration          "Timeout to use for Memcached Connections"
-	myPool  *ConnectionPoolWrapper "Connection Pool wrapper"
+	Mode    ConnectionMode                      "How should we prepare the connection pool?"
+	Size    int                                 "(Max) Pool size, split between the Urls by weight, every Url needs at least one"
+	Urls    []string                            "Memcached URLs to connect to"
+	Logger  Logger                              "(optional) Logger we are using in the connection pool, nil logs nothing"
+	Timeout time.Duration                       "Timeout to use for Memcached Connections, and to wait for a connection to a key's Url"
+	myPool  *weightedPool[*MemcachedConnection] "Connection Pool"
+	myStats *connectionStats                    "Counters shared with the connections"
+	mutex   sync.RWMutex                        "Guards myPool, myStats, and the defaults Open sets for doKey"
+	opening sync.Mutex                          "Serializes Open, Close and Shutdown"
+
+	DialTimeout  time.Duration "(optional) Timeout for dialing Memcached, defaults to Timeout"
+	ReadTimeout  time.Duration "(optional) Timeout for reading each reply, defaults to Timeout"
+	WriteTimeout time.Duration "(optional) Timeout for writing each command, defaults to Timeout"
+
+	HealthCheckInterval time.Duration "(optional) How often to Ping the idle connections, 0 disables the health checks"
+	MaxIdleTime         time.Duration "(optional) Re-open connections idle longer than this, even without the health checks, 0 disables the limit"
+	MaxLifetime         time.Duration "(optional) Re-open connections older than this when they are Pop'd or Push'd, 0 disables the limit"
+	MaxLifetimeJitter   time.Duration "(optional) Re-open connections up to this much before MaxLifetime, defaults to 10% of MaxLifetime"
+
+	MinIdle int "(optional) Number of idle connections to keep open when MaxOpen is set"
+	MaxOpen int "(optional) Open connections on demand up to this many, 0 opens Size connections up front"
+
+	LeakThreshold time.Duration "(optional) Log connections borrowed longer than this with the stack that Pop'd them, 0 disables leak detection"
+
+	WarmUp     WarmUpPolicy "(optional) What to do when AGRESSIVE connections fail to open, defaults to WARMUP_FAIL_FAST"
+	MinHealthy float64      "(optional) Fraction of the AGRESSIVE connections that must open with WARMUP_MIN_HEALTHY"
+	myWarmUp   WarmUpReport "Which connections opened during the last Open"
+
+	Hooks PoolHooks[*MemcachedConnection] "(optional) Callbacks for the pool's and the connections' lifecycle events"
+
+	BreakerThreshold int           "(optional) Fail fast on a Url after this many consecutive dial/fatal errors, 0 disables the circuit breakers"
+	BreakerCoolDown  time.Duration "(optional) How long to fail fast before probing the Url again, defaults to 10s"
+
+	TLSConfig *tls.Config "(optional) Dial the Urls with TLS, for memcached servers started with -Z"
+
+	Retry *RetryPolicy "(optional) Retry policy for the connections, nil disables the retries"
+}
+
+//
+// Logger to log to, NopLogger when Logger is nil
+//
+func (p *MemcachedConnectionPool) logger() leveledLogger {
+	return logTo(p.Logger)
 }
 
 //
 // Is the pool open?
 //
 func (p *MemcachedConnectionPool) IsOpen() bool {
-	return nil != p.myPool
+	pool, _ := p.pool()
+	return nil != pool && pool.IsOpen()
 }
 
 //
 // Is the pool closed?
 //
 func (p *MemcachedConnectionPool) IsClosed() bool {
-	return nil == p.myPool
+	return !p.IsOpen()
 }
 
 //
@@ -40,60 +85,195 @@ func (p *MemcachedConnectionPool) IsClosed() bool {
 // Returns -1 if the pool is not open
 //
 func (p *MemcachedConnectionPool) Len() int {
-	if p.IsOpen() {
-		return p.myPool.Len()
+	if pool, _ := p.pool(); nil != pool {
+		return pool.Len()
 	}
 	return -1
 }
 
+//
+// Snapshot of the pool's size and counters
+// Returns an empty snapshot if the pool is not open
+//
+func (p *MemcachedConnectionPool) Stats() PoolStats {
+	pool, stats := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return PoolStats{}
+	}
+	return stats.addTo(pool.Stats())
+}
+
+//
+// Connections that are currently borrowed from the pool, oldest first
+// Returns nil if the pool is not open
+//
+func (p *MemcachedConnectionPool) Outstanding() []BorrowedObject[*MemcachedConnection] {
+	if pool, _ := p.pool(); nil != pool {
+		return pool.Outstanding()
+	}
+	return nil
+}
+
+//
+// State of each Url's circuit breaker
+// Returns nil if the pool is not open
+//
+func (p *MemcachedConnectionPool) CircuitStates() map[string]CircuitState {
+	if pool, _ := p.pool(); nil != pool {
+		return pool.CircuitStates()
+	}
+	return nil
+}
+
+//
+// Which connections opened during the last Open?
+//
+func (p *MemcachedConnectionPool) WarmUpReport() WarmUpReport {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.myWarmUp
+}
+
+func (p *MemcachedConnectionPool) setWarmUpReport(report WarmUpReport) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.myWarmUp = report
+}
+
 //
 // Open the connection pool
 //
+// Re-opening an open pool swaps in the new connections before closing the old ones,
+// connections Pop'd from the old pool are closed when they are Push'd back.
+//
 func (p *MemcachedConnectionPool) Open() error {
-	p.Close()
+	p.opening.Lock()
+	defer p.opening.Unlock()
+
+	// Set the defaults under the mutex, doKey reads the Timeout while the pool is in use
+	p.mutex.Lock()
 
-	// Default to 15s timeout
+	// Default to the 15s timeout
 	if time.Duration(0) == p.Timeout {
-		p.Timeout = time.Duration(15) * time.Second
+		p.Timeout = default_timeout
+	}
+
+	// Default to 10s cool-down for the circuit breakers
+	if time.Duration(0) == p.BreakerCoolDown {
+		p.BreakerCoolDown = time.Duration(10) * time.Second
 	}
+	p.mutex.Unlock()
+
+	// Counters shared by the connections
+	stats := &connectionStats{}
 
-	// Lambda to iterate the urls
-	nextUrl := loopStrings(p.Urls)
+	// Check the WarmUp policy
+	if p.MinHealthy < 0 || p.MinHealthy > 1 {
+		p.close()
+		return fmt.Errorf("[MemcachedConnectionPool][Open] MinHealthy[%v] must be between 0 and 1!", p.MinHealthy)
+	}
+	warm_up := makeWarmUp(p.WarmUp, nil)
 
 	// Lambda for creating the factories
-	var initfn InitFunction
+	var initfn func(nextUrl func() []string, breaker *circuitBreaker) func() (*MemcachedConnection, error)
 	switch p.Mode {
 	case LAZY:
 		// Create the factory
 		// DON'T Connect to Memcached
 		// DON'T Test the connection
-		initfn = func() (interface{}, error) {
-			values := nextUrl()
-			return makeLazyMemcachedConnection(values[0], values[1], p.Timeout, &p.Logger)
+		initfn = func(nextUrl func() []string, breaker *circuitBreaker) func() (*MemcachedConnection, error) {
+			return func() (*MemcachedConnection, error) {
+				values := nextUrl()
+				return makeLazyMemcachedConnection(values[0], values[1], p.timeouts(), p.TLSConfig, p.Logger, stats, &p.Hooks.ConnectionHooks, breaker)
+			}
 		}
 	case AGRESSIVE:
 		// Create the factory
 		// AND Connect to Memcached
 		// AND Test the connection
-		initfn = func() (interface{}, error) {
-			values := nextUrl()
-			return makeAgressiveMemcachedConnection(values[0], values[1], p.Timeout, &p.Logger)
+		// Failures while warming up are handled by the WarmUp policy
+		initfn = func(nextUrl func() []string, breaker *circuitBreaker) func() (*MemcachedConnection, error) {
+			return makeWarmUpFactory(warm_up, nextUrl,
+				func(url, id string) (*MemcachedConnection, error) {
+					return makeAgressiveMemcachedConnection(url, id, p.timeouts(), p.TLSConfig, p.Logger, stats, &p.Hooks.ConnectionHooks, breaker)
+				},
+				func(url, id string) (*MemcachedConnection, error) {
+					return makeLazyMemcachedConnection(url, id, p.timeouts(), p.TLSConfig, p.Logger, stats, &p.Hooks.ConnectionHooks, breaker)
+				})
 		}
 		// No mode specified!
 	default:
+		p.close()
 		return errors.New(fmt.Sprintf("Invalid connection mode: %v", p.Mode))
 	}
 
-	// Create the new pool
-	pool, err := MakeConnectionPoolWrapper(p.Size, initfn)
+	// Create a sub-pool for each url, splitting the Size, MinIdle and MaxOpen by weight
+	pool, err := makeWeightedPool(p.Urls, p.Size, p.MinIdle, p.MaxOpen, func(c *MemcachedConnection) string { return c.Url }, func(url string) (*Pool[*MemcachedConnection], *circuitBreaker) {
+		breaker := makeCircuitBreaker(p.BreakerThreshold, p.BreakerCoolDown)
+		factory := initfn(loopStrings([]string{url}), breaker)
+		return &Pool[*MemcachedConnection]{
+			Factory: func() (*MemcachedConnection, error) {
+				c, err := factory()
+				if nil == err {
+					c.Retry = p.Retry
+					p.Hooks.created(c)
+				}
+				return c, err
+			},
+			Destroy: func(c *MemcachedConnection) { c.Close() },
+
+			// Ping the idle connections, re-opening any that were closed
+			HealthCheck:         func(c *MemcachedConnection) error { return c.Ping() },
+			HealthCheckInterval: p.HealthCheckInterval,
+			MaxIdleTime:         p.MaxIdleTime,
+
+			// Re-open old connections, staggered by the jitter
+			MaxLifetime:       p.MaxLifetime,
+			MaxLifetimeJitter: p.MaxLifetimeJitter,
+
+			// Log the connections that are borrowed too long
+			LeakThreshold: p.LeakThreshold,
+			OnLeak: func(b BorrowedObject[*MemcachedConnection]) {
+				p.logger().Warn("[MemcachedConnectionPool][Leak][%s/%s] Connection borrowed since %v by:\n%s", b.Value.Url, b.Value.Id, b.BorrowedAt, b.Stack)
+			},
+		}, breaker
+	})
+	if nil != err {
+		p.close()
+		return err
+	}
+
+	// Route the keys to the urls, weighted like the sub-pools
+	if err := pool.useKetama(); nil != err {
+		p.close()
+		return err
+	}
 
 	// Error creating the pool?
+	err = pool.Open()
+
+	// Check the connections that opened against the WarmUp policy
+	report, warm_up_err := warm_up.finish(p.MinHealthy)
+	p.setWarmUpReport(report)
+	if report.Failed > 0 {
+		p.logger().Warn("[MemcachedConnectionPool][Open] Connections failed to open, report=%v", report)
+	}
+	if nil == err && nil != warm_up_err {
+		pool.Close()
+		err = warm_up_err
+	}
+
 	if nil != err {
+		p.close()
 		return err
 	}
 
-	// Save the pointer to the pool
-	p.myPool = pool
+	// Swap in the new pool, and close the previous one
+	if previous := p.swap(pool, stats); nil != previous {
+		previous.Close()
+	}
 
 	// Return nil
 	return nil
@@ -103,47 +283,356 @@ func (p *MemcachedConnectionPool) Open() error {
 // Close the connection pool
 //
 func (p *MemcachedConnectionPool) Close() {
-	if p.IsClosed() {
+	p.opening.Lock()
+	defer p.opening.Unlock()
+
+	p.close()
+}
+
+func (p *MemcachedConnectionPool) close() {
+	// Release the connection pool
+	previous := p.swap(nil, nil)
+	if nil == previous {
 		return
 	}
 
-	// If the pool is not nil,
-	// Then close all the connections and release the pointer
-	if nil != p.myPool {
-		for i := 0; i < p.Size; i++ {
-			// Pop a connection from the pool
-			c, _ := p.Pop()
+	// Close all the idle connections,
+	// Borrowed connections are closed when they are Push'd back
+	previous.Close()
+}
 
-			// Close the connection
-			if nil != c {
-				c.Close()
-			}
-		}
+//
+// Close the connection pool, and wait for the borrowed connections to be Push'd back and closed
+//
+// Returns ErrPoolTimeout if the context's deadline expires first,
+// the remaining connections are closed when they are Push'd back.
+//
+func (p *MemcachedConnectionPool) Shutdown(ctx context.Context) error {
+	p.opening.Lock()
+	defer p.opening.Unlock()
+
+	pool, _ := p.pool()
+	if nil == pool {
+		return nil
+	}
+
+	// Stop the Pop's, and wait for the Push's
+	err := pool.Shutdown(ctx)
+	if nil != err {
+		p.logger().Warn("[MemcachedConnectionPool][Shutdown] Connections are still borrowed urls=%v, err=%v", p.Urls, err)
 	}
 
 	// Release the connection pool
-	p.myPool = nil
+	p.swap(nil, nil)
+	return err
+}
+
+//
+// Timeouts for the connections, the Dial, Read and Write timeouts fall back to Timeout
+//
+func (p *MemcachedConnectionPool) timeouts() connectionTimeouts {
+	return connectionTimeouts{Timeout: p.Timeout, Dial: p.DialTimeout, Read: p.ReadTimeout, Write: p.WriteTimeout}
+}
+
+//
+// How long doKey waits for a connection to the key's Url
+//
+func (p *MemcachedConnectionPool) popTimeout() time.Duration {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.Timeout
+}
+
+//
+// The open pool and its counters, nil if the pool is not open
+//
+func (p *MemcachedConnectionPool) pool() (*weightedPool[*MemcachedConnection], *connectionStats) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.myPool, p.myStats
+}
+
+//
+// Replace the pool and its counters, returning the previous pool
+//
+func (p *MemcachedConnectionPool) swap(pool *weightedPool[*MemcachedConnection], stats *connectionStats) *weightedPool[*MemcachedConnection] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	previous := p.myPool
+	p.myPool, p.myStats = pool, stats
+	return previous
 }
 
 //
 // Get a MemcachedConnection from the pool
 //
 func (p *MemcachedConnectionPool) Pop() (*MemcachedConnection, error) {
-	// Pop a connection from the pool
-	c := p.myPool.GetConnection()
+	pool, _ := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return nil, ErrConnectionIsClosed
+	}
+
+	// Pop a connection from the pool,
+	// Returns an error when all connections are exhausted
+	c, err := pool.Get()
+	if nil == err {
+		p.Hooks.borrowed(c)
+	}
+	return c, err
+}
+
+//
+// Url that owns the key on the Ketama continuum
+// Returns "" if the pool is not open
+//
+func (p *MemcachedConnectionPool) UrlOf(key string) string {
+	if pool, _ := p.pool(); nil != pool {
+		return pool.UrlOf(key)
+	}
+	return ""
+}
+
+//
+// Get a MemcachedConnection to the Url that owns the key
+//
+// The key always maps to the same Url, even when its circuit breaker is open or its connections are exhausted.
+//
+func (p *MemcachedConnectionPool) PopKey(key string) (*MemcachedConnection, error) {
+	pool, _ := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return nil, ErrConnectionIsClosed
+	}
+
+	// Pop a connection from the key's sub-pool,
+	// Returns an error when its connections are exhausted
+	c, err := pool.GetKey(key)
+	if nil == err {
+		p.Hooks.borrowed(c)
+	}
+	return c, err
+}
+
+//
+// Get a MemcachedConnection to the Url that owns the key, waiting for one to be returned
+// Returns ErrPoolTimeout if the context's deadline expires first
+//
+func (p *MemcachedConnectionPool) PopKeyContext(ctx context.Context, key string) (*MemcachedConnection, error) {
+	pool, _ := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return nil, ErrConnectionIsClosed
+	}
 
-	// Return the connection
-	if c != nil {
-		return c.(*MemcachedConnection), nil
+	// Wait for a connection from the key's sub-pool,
+	// Returns an error when the context is done first
+	c, err := pool.GetKeyContext(ctx, key)
+	if nil == err {
+		p.Hooks.borrowed(c)
 	}
+	return c, err
+}
+
+//
+// Get a MemcachedConnection to the Url that owns the key, waiting at most timeout for one to be returned
+//
+func (p *MemcachedConnectionPool) PopKeyTimeout(key string, timeout time.Duration) (*MemcachedConnection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// Return an error when all connections are exhausted
-	return nil, ErrNoConnectionsAvailable
+	return p.PopKeyContext(ctx, key)
+}
+
+//
+// Get a MemcachedConnection from the pool, waiting for one to be returned
+// Returns ErrPoolTimeout if the context's deadline expires first
+//
+func (p *MemcachedConnectionPool) PopContext(ctx context.Context) (*MemcachedConnection, error) {
+	pool, _ := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return nil, ErrConnectionIsClosed
+	}
+
+	// Wait for a connection from the pool,
+	// Returns an error when the context is done first
+	c, err := pool.GetContext(ctx)
+	if nil == err {
+		p.Hooks.borrowed(c)
+	}
+	return c, err
+}
+
+//
+// Get a MemcachedConnection from the pool, waiting at most timeout for one to be returned
+//
+func (p *MemcachedConnectionPool) PopTimeout(timeout time.Duration) (*MemcachedConnection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return p.PopContext(ctx)
 }
 
 //
 // Return a MemcachedConnection
 //
 func (p *MemcachedConnectionPool) Push(c *MemcachedConnection) {
-	p.myPool.ReleaseConnection(c)
+	p.Hooks.returned(c)
+
+	// The pool is closed, or the connection is from before the pool was re-opened,
+	// close the connection instead
+	pool, stats := p.pool()
+	if nil == pool || c.stats != stats {
+		c.Close()
+		return
+	}
+
+	// Closes the connection if the pool is shutting down
+	pool.Release(c)
+}
+
+//
+// Pop a MemcachedConnection, call fn with it, and Push it back
+//
+// Connections closed by a fatal error, or by a panic in fn, are discarded
+// instead of being returned to the pool. Panics are returned as errors.
+//
+func (p *MemcachedConnectionPool) Do(fn func(*MemcachedConnection) error) (err error) {
+	c, err := p.Pop()
+	if nil != err {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); nil != r {
+			p.logger().Critical("[MemcachedConnectionPool][Do] Panic Error = '%v'", r)
+			c.Close()
+			err = panicError(r)
+		}
+		p.pushOrDiscard(c)
+	}()
+
+	return fn(c)
+}
+
+//
+// Return an open connection to the pool, and discard a closed one
+//
+func (p *MemcachedConnectionPool) pushOrDiscard(c *MemcachedConnection) {
+	pool, stats := p.pool()
+	if nil == pool || c.stats != stats || c.IsOpen() {
+		p.Push(c)
+		return
+	}
+
+	p.logger().Finest("[MemcachedConnectionPool][Do][%s/%s] Discarded closed connection", c.Url, c.Id)
+	p.Hooks.returned(c)
+	pool.Discard(c)
+}
+
+//
+// Pop the connection for the key, waiting at most Timeout for one, call fn with it, and Push it back
+//
+func (p *MemcachedConnectionPool) doKey(key string, fn func(*MemcachedConnection) error) error {
+	c, err := p.PopKeyTimeout(key, p.popTimeout())
+	if nil != err {
+		return err
+	}
+	defer p.pushOrDiscard(c)
+
+	return fn(c)
+}
+
+//
+//  ========================================
+//
+// MemcachedClientInterface implementation:
+//
+//  ========================================
+//
+
+//
+// GetMulti splits the keys between the Urls that own them, and merges the items
+//
+func (p *MemcachedConnectionPool) GetMulti(keys []string) (map[string]*memcached.Item, error) {
+	// Group the keys by Url, in the order the Urls are first seen
+	urls := []string{}
+	url_keys := make(map[string][]string)
+	for _, key := range keys {
+		url := p.UrlOf(key)
+		if _, ok := url_keys[url]; !ok {
+			urls = append(urls, url)
+		}
+		url_keys[url] = append(url_keys[url], key)
+	}
+
+	output := make(map[string]*memcached.Item, len(keys))
+	for _, url := range urls {
+		group := url_keys[url]
+		err := p.doKey(group[0], func(c *MemcachedConnection) error {
+			items, err := c.GetMulti(group)
+			for key, item := range items {
+				output[key] = item
+			}
+			return err
+		})
+		if nil != err {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+//
+// Get gets the item for the key from the Url that owns it
+//
+func (p *MemcachedConnectionPool) Get(key string) (item *memcached.Item, err error) {
+	err = p.doKey(key, func(c *MemcachedConnection) error {
+		item, err = c.Get(key)
+		return err
+	})
+	return item, err
+}
+
+//
+// Set writes the item to the Url that owns its key
+//
+func (p *MemcachedConnectionPool) Set(item *memcached.Item) error {
+	return p.doKey(item.Key, func(c *MemcachedConnection) error { return c.Set(item) })
+}
+
+//
+// Delete deletes the key from the Url that owns it
+//
+func (p *MemcachedConnectionPool) Delete(key string) error {
+	return p.doKey(key, func(c *MemcachedConnection) error { return c.Delete(key) })
+}
+
+//
+// Add writes the item to the Url that owns its key, if no value already exists for the key
+//
+func (p *MemcachedConnectionPool) Add(item *memcached.Item) error {
+	return p.doKey(item.Key, func(c *MemcachedConnection) error { return c.Add(item) })
+}
+
+//
+// Increment increments the key on the Url that owns it
+//
+func (p *MemcachedConnectionPool) Increment(key string, delta uint64) (newValue uint64, err error) {
+	err = p.doKey(key, func(c *MemcachedConnection) error {
+		newValue, err = c.Increment(key, delta)
+		return err
+	})
+	return newValue, err
+}
+
+//
+// Decrement decrements the key on the Url that owns it
+//
+func (p *MemcachedConnectionPool) Decrement(key string, delta uint64) (newValue uint64, err error) {
+	err = p.doKey(key, func(c *MemcachedConnection) error {
+		newValue, err = c.Decrement(key, delta)
+		return err
+	})
+	return newValue, err
 }
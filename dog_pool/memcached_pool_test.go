@@ -1,8 +1,14 @@
 package dog_pool
 
+import "context"
+import "fmt"
+import "strings"
+import "sync"
 import "testing"
+import "time"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
-import "github.com/alecthomas/log4go"
+import memcached "github.com/bradfitz/gomemcache/memcache"
 
 func TestMemcachedPoolSpecs(t *testing.T) {
 	if testing.Short() {
@@ -16,7 +22,7 @@ func TestMemcachedPoolSpecs(t *testing.T) {
 
 // Helpers
 func MemcachedPoolSpecs(c gospec.Context) {
-	var memcached_pool_logger = log4go.NewDefaultLogger(log4go.CRITICAL)
+	var memcached_pool_logger = MakeStdLogger(log.Default(), LOG_CRITICAL)
 
 	c.Specify("[MemcachedConnectionPool] New Pool is not open", func() {
 		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 0, Urls: []string{}, Logger: memcached_pool_logger}
@@ -78,6 +84,67 @@ func MemcachedPoolSpecs(c gospec.Context) {
 		c.Expect(connection, gospec.Satisfies, nil == connection)
 	})
 
+	c.Specify("[MemcachedConnectionPool] PopTimeout from empty pool returns timeout error", func() {
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 0, Urls: []string{}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		// Shouldn't have any errors
+		err := pool.Open()
+		c.Expect(err, gospec.Equals, nil)
+
+		connection, err := pool.PopTimeout(time.Duration(10) * time.Millisecond)
+		c.Expect(err, gospec.Equals, ErrPoolTimeout)
+		c.Expect(connection, gospec.Satisfies, nil == connection)
+	})
+
+	c.Specify("[MemcachedConnectionPool] PopTimeout from closed pool returns error", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11395"}, Logger: memcached_pool_logger}
+
+		connection, err := pool.PopTimeout(time.Duration(10) * time.Millisecond)
+		c.Expect(err, gospec.Equals, ErrConnectionIsClosed)
+		c.Expect(connection, gospec.Satisfies, nil == connection)
+	})
+
+	c.Specify("[MemcachedConnectionPool] PopTimeout waits for a connection to be Pushed", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11395"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Borrow the only connection
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 0)
+
+		// Return the connection after a short delay
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			pool.Push(connection)
+		}()
+
+		waited, err := pool.PopTimeout(time.Second)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(waited, gospec.Equals, connection)
+		pool.Push(waited)
+	})
+
+	c.Specify("[MemcachedConnectionPool] PopContext returns when the context is cancelled", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 0, Urls: []string{}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			cancel()
+		}()
+
+		connection, err := pool.PopContext(ctx)
+		c.Expect(err, gospec.Equals, context.Canceled)
+		c.Expect(connection, gospec.Satisfies, nil == connection)
+	})
+
 	c.Specify("[MemcachedConnectionPool] Opening connection to Invalid Host/Port has errors", func() {
 		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger}
 		defer pool.Close()
@@ -92,7 +159,7 @@ func MemcachedPoolSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnectionPool] Opening connection to Valid Host/Port has no errors", func() {
-		server, err := StartMemcachedServer(&memcached_pool_logger)
+		server, err := StartMemcachedServer(memcached_pool_logger)
 		if nil != err {
 			panic(err)
 		}
@@ -108,7 +175,7 @@ func MemcachedPoolSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnectionPool] 10x AGRESSIVE Pool Pops 10x open connections", func() {
-		server, err := StartMemcachedServer(&memcached_pool_logger)
+		server, err := StartMemcachedServer(memcached_pool_logger)
 		if nil != err {
 			panic(err)
 		}
@@ -135,7 +202,7 @@ func MemcachedPoolSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MemcachedConnectionPool] 10x LAZY Pool Pops 10x closed connections", func() {
-		server, err := StartMemcachedServer(&memcached_pool_logger)
+		server, err := StartMemcachedServer(memcached_pool_logger)
 		if nil != err {
 			panic(err)
 		}
@@ -160,4 +227,642 @@ func MemcachedPoolSpecs(c gospec.Context) {
 			c.Expect(connection.IsClosed(), gospec.Equals, true)
 		}
 	})
+
+	c.Specify("[MemcachedConnectionPool] HealthCheck re-opens closed idle connections", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Url()}, Logger: memcached_pool_logger, HealthCheckInterval: time.Duration(10) * time.Millisecond}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Close the connection and return it to the pool
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		connection.Close()
+		pool.Push(connection)
+
+		// Wait for the health check
+		time.Sleep(time.Duration(50) * time.Millisecond)
+
+		connection, err = pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.IsOpen(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnectionPool] HealthCheck replaces connections idle longer than MaxIdleTime", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Url()}, Logger: memcached_pool_logger, HealthCheckInterval: time.Duration(10) * time.Millisecond, MaxIdleTime: time.Duration(20) * time.Millisecond}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		pool.Push(connection)
+
+		// Wait for the health check
+		time.Sleep(time.Duration(100) * time.Millisecond)
+
+		replacement, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(replacement, gospec.Satisfies, replacement != connection)
+		c.Expect(replacement.IsOpen(), gospec.Equals, true)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnectionPool] MaxLifetime re-opens old connections when they are Push'd", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Url()}, Logger: memcached_pool_logger, MaxLifetime: time.Duration(20) * time.Millisecond}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		time.Sleep(time.Duration(30) * time.Millisecond)
+		pool.Push(connection)
+
+		// The old connection was closed, and a new one opened
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+
+		replacement, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(replacement, gospec.Satisfies, replacement != connection)
+		c.Expect(replacement.IsOpen(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Connections get the pool's timeouts", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger, DialTimeout: time.Second, ReadTimeout: time.Minute}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Timeout, gospec.Equals, time.Duration(15)*time.Second)
+		c.Expect(connection.DialTimeout, gospec.Equals, time.Second)
+		c.Expect(connection.ReadTimeout, gospec.Equals, time.Minute)
+		c.Expect(connection.WriteTimeout, gospec.Equals, time.Duration(0))
+
+		// The Write timeout falls back to the Timeout
+		c.Expect(connection.timeouts().write(), gospec.Equals, time.Duration(15)*time.Second)
+		c.Expect(connection.Clone().timeouts(), gospec.Equals, connection.timeouts())
+	})
+
+	c.Specify("[MemcachedConnectionPool] Retry retries the reads that fail on network errors", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger, Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Retry, gospec.Equals, pool.Retry)
+
+		// Get's are retried
+		_, err = connection.Get("bob")
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(3))
+
+		_, err = connection.GetMulti([]string{"bob", "gary"})
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(6))
+
+		// Writes are never retried
+		_, err = connection.Increment("bob", 1)
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(7))
+	})
+
+	c.Specify("[MemcachedConnectionPool] Stats counts the Pops, waits and dial errors", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Stats(), gospec.Equals, PoolStats{})
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Lazy connections dial on their first command
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		err = connection.Ping()
+		c.Expect(err, gospec.Satisfies, nil != err)
+
+		// The only connection is borrowed
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrNoConnectionsAvailable)
+		_, err = pool.PopTimeout(time.Duration(10) * time.Millisecond)
+		c.Expect(err, gospec.Equals, ErrPoolTimeout)
+
+		stats := pool.Stats()
+		c.Expect(stats.Size, gospec.Equals, 1)
+		c.Expect(stats.Idle, gospec.Equals, 0)
+		c.Expect(stats.InUse, gospec.Equals, 1)
+		c.Expect(stats.PopSuccesses, gospec.Equals, uint64(1))
+		c.Expect(stats.PopFailures, gospec.Equals, uint64(2))
+		c.Expect(stats.Waits, gospec.Equals, uint64(1))
+		c.Expect(stats.WaitTime, gospec.Satisfies, stats.WaitTime >= time.Duration(10)*time.Millisecond)
+		c.Expect(stats.DialErrors, gospec.Equals, uint64(1))
+		c.Expect(stats.FatalErrors, gospec.Equals, uint64(0))
+
+		pool.Push(connection)
+		c.Expect(pool.Stats().Idle, gospec.Equals, 1)
+		c.Expect(pool.Stats().InUse, gospec.Equals, 0)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Stats counts the connections closed by fatal errors", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Url()}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		// Stop the server under the open connection
+		server.Close()
+		err = connection.Ping()
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+
+		stats := pool.Stats()
+		c.Expect(stats.DialErrors, gospec.Equals, uint64(0))
+		c.Expect(stats.FatalErrors, gospec.Equals, uint64(1))
+	})
+
+	c.Specify("[MemcachedConnectionPool] MaxOpen opens MinIdle connections, and grows on demand up to MaxOpen", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 500, Urls: []string{server.Url()}, Logger: memcached_pool_logger, MinIdle: 1, MaxOpen: 2}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 1)
+
+		connection1, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		connection2, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection2.IsOpen(), gospec.Equals, true)
+
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrNoConnectionsAvailable)
+
+		pool.Push(connection1)
+		pool.Push(connection2)
+		c.Expect(pool.Len(), gospec.Equals, 2)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Do returns the connection to the pool", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Url()}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		err = pool.Do(func(connection *MemcachedConnection) error {
+			c.Expect(pool.Len(), gospec.Equals, 0)
+			return connection.SetStr("do-key", "1", 10)
+		})
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 1)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Do discards connections closed by fatal errors", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Url()}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Stop the server under the open connection
+		server.Close()
+		err = pool.Do(func(connection *MemcachedConnection) error {
+			return connection.Ping()
+		})
+		c.Expect(err, gospec.Satisfies, nil != err)
+
+		stats := pool.Stats()
+		c.Expect(stats.Idle, gospec.Equals, 0)
+		c.Expect(stats.InUse, gospec.Equals, 0)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Do recovers panics and discards the connection", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		err := pool.Do(func(connection *MemcachedConnection) error {
+			panic("Panic in Do")
+		})
+		c.Expect(err, gospec.Satisfies, nil != err && "Panic in Do" == err.Error())
+
+		stats := pool.Stats()
+		c.Expect(stats.Idle, gospec.Equals, 0)
+		c.Expect(stats.InUse, gospec.Equals, 0)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Outstanding lists the connections with the stack that Pop'd them", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger, LeakThreshold: time.Hour}
+		defer pool.Close()
+
+		c.Expect(len(pool.Outstanding()), gospec.Equals, 0)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		outstanding := pool.Outstanding()
+		c.Expect(len(outstanding), gospec.Equals, 1)
+		c.Expect(outstanding[0].Value, gospec.Equals, connection)
+		c.Expect(outstanding[0].Stack, gospec.Satisfies, strings.Contains(outstanding[0].Stack, "MemcachedPoolSpecs"))
+
+		pool.Push(connection)
+		c.Expect(len(pool.Outstanding()), gospec.Equals, 0)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Shutdown waits for the borrowed connections to be Push'd", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Shutdown(context.Background()), gospec.Equals, nil)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		// Push the connection after a short delay
+		pushed := make(chan bool, 1)
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			pushed <- true
+			pool.Push(connection)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		c.Expect(pool.Shutdown(ctx), gospec.Equals, nil)
+		c.Expect(len(pushed), gospec.Equals, 1)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrConnectionIsClosed)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Shutdown times out, and late Push's close the connection", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Url()}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+		defer cancel()
+
+		c.Expect(pool.Shutdown(ctx), gospec.Equals, ErrPoolTimeout)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+		c.Expect(connection.IsOpen(), gospec.Equals, true)
+
+		pool.Push(connection)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Concurrent Open/Close/Pop/Push are safe, run with go test -race", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 4, Urls: []string{server.Url()}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		var wg sync.WaitGroup
+		done := make(chan bool)
+
+		// Pop, use and Push connections until the pool stops changing
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+
+					pool.IsOpen()
+					pool.Len()
+					connection, err := pool.PopTimeout(time.Millisecond)
+					if nil != err {
+						continue
+					}
+					connection.Ping()
+					pool.Push(connection)
+				}
+			}()
+		}
+
+		// Re-open and close the pool, like a config reload
+		for i := 0; i < 20; i++ {
+			if 0 == i%5 {
+				pool.Close()
+			} else {
+				pool.Open()
+			}
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+		wg.Wait()
+
+		// The pool still works afterwards
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Ping(), gospec.Equals, nil)
+		pool.Push(connection)
+		c.Expect(pool.Len(), gospec.Equals, 4)
+	})
+
+	c.Specify("[MemcachedConnectionPool] WARMUP_FAIL_FAST reports the URL that failed", func() {
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		err := pool.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+
+		report := pool.WarmUpReport()
+		c.Expect(report.Failed, gospec.Equals, 1)
+		c.Expect(report.Errors["127.0.0.1:11391"], gospec.Equals, err)
+	})
+
+	c.Specify("[MemcachedConnectionPool] WARMUP_LAZY_FILL opens the pool when some URLs are down", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 4, Urls: []string{server.Url(), "127.0.0.1:11391"}, Logger: memcached_pool_logger, WarmUp: WARMUP_LAZY_FILL}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 4)
+
+		report := pool.WarmUpReport()
+		c.Expect(report.Opened, gospec.Equals, 2)
+		c.Expect(report.Failed, gospec.Equals, 2)
+		c.Expect(report.FailedUrls(), gospec.Satisfies, 1 == len(report.FailedUrls()) && "127.0.0.1:11391" == report.FailedUrls()[0])
+	})
+
+	c.Specify("[MemcachedConnectionPool] WARMUP_MIN_HEALTHY fails when too few connections open", func() {
+		server, err := StartMemcachedServer(memcached_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := MemcachedConnectionPool{Mode: AGRESSIVE, Size: 4, Urls: []string{server.Url(), "127.0.0.1:11391"}, Logger: memcached_pool_logger, WarmUp: WARMUP_MIN_HEALTHY, MinHealthy: 0.5}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.WarmUpReport().Healthy(), gospec.Equals, float64(0.5))
+
+		pool.MinHealthy = 0.75
+		c.Expect(pool.Open(), gospec.Equals, ErrWarmUpFailed)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Hooks are called when connections are created, Pop'd and Push'd", func() {
+		created, borrowed, returned := 0, 0, 0
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger}
+		pool.Hooks.OnCreate = func(*MemcachedConnection) { created++ }
+		pool.Hooks.OnBorrow = func(*MemcachedConnection) { borrowed++ }
+		pool.Hooks.OnReturn = func(*MemcachedConnection) { returned++ }
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(created, gospec.Equals, 2)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(borrowed, gospec.Equals, 1)
+		c.Expect(connection.Hooks, gospec.Equals, &pool.Hooks.ConnectionHooks)
+
+		pool.Push(connection)
+		c.Expect(returned, gospec.Equals, 1)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Splits the pool between the Urls by weight", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:11391;weight=3", "127.0.0.1:11392"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		counts := map[string]int{}
+		connections := []*MemcachedConnection{}
+		for i := 0; i < 4; i++ {
+			connection, err := pool.Pop()
+			c.Expect(err, gospec.Equals, nil)
+			counts[connection.Url]++
+			connections = append(connections, connection)
+		}
+		c.Expect(counts, gospec.Equals, map[string]int{"127.0.0.1:11391": 3, "127.0.0.1:11392": 1})
+
+		for _, connection := range connections {
+			pool.Push(connection)
+		}
+		c.Expect(pool.Len(), gospec.Equals, 4)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Invalid Url weights fail to Open", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:11391;weight=0"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		err := pool.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Circuit breaker fails fast after BreakerThreshold dial errors", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11391"}, Logger: memcached_pool_logger, BreakerThreshold: 2, BreakerCoolDown: time.Hour}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.CircuitStates(), gospec.Equals, map[string]CircuitState{"127.0.0.1:11391": CIRCUIT_CLOSED})
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		err = connection.Open()
+		c.Expect(err, gospec.Satisfies, nil != err && ErrCircuitOpen != err)
+		err = connection.Open()
+		c.Expect(err, gospec.Satisfies, nil != err && ErrCircuitOpen != err)
+		c.Expect(pool.CircuitStates(), gospec.Equals, map[string]CircuitState{"127.0.0.1:11391": CIRCUIT_OPEN})
+
+		// Fails fast without dialing
+		c.Expect(connection.Open(), gospec.Equals, ErrCircuitOpen)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(2))
+
+		// Pop fails fast too
+		pool.Push(connection)
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrCircuitOpen)
+		c.Expect(pool.Stats().PopFailures, gospec.Equals, uint64(1))
+	})
+
+	c.Specify("[MemcachedConnectionPool] Circuit breaker routes Pop's to the other Urls", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:11391", "127.0.0.1:11392"}, Logger: memcached_pool_logger, BreakerThreshold: 1, BreakerCoolDown: time.Hour}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Trip the first url's breaker, the full pool has connections to both urls
+		connections := []*MemcachedConnection{}
+		for i := 0; i < 4; i++ {
+			connection, err := pool.Pop()
+			c.Expect(err, gospec.Equals, nil)
+			connections = append(connections, connection)
+		}
+		for _, connection := range connections {
+			if "127.0.0.1:11391" == connection.Url {
+				connection.Open()
+			}
+			pool.Push(connection)
+		}
+		c.Expect(pool.CircuitStates()["127.0.0.1:11391"], gospec.Equals, CIRCUIT_OPEN)
+
+		for i := 0; i < 10; i++ {
+			connection, err := pool.Pop()
+			c.Expect(err, gospec.Equals, nil)
+			c.Expect(connection.Url, gospec.Equals, "127.0.0.1:11392")
+			pool.Push(connection)
+		}
+	})
+
+	c.Specify("[MemcachedConnectionPool] PopKey always Pops a connection to the Url that owns the key", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:11391", "127.0.0.1:11392"}, Logger: memcached_pool_logger}
+		c.Expect(pool.UrlOf("bob"), gospec.Equals, "")
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		counts := map[string]int{}
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("key:%d", i)
+			connection, err := pool.PopKey(key)
+			c.Expect(err, gospec.Equals, nil)
+			c.Expect(connection.Url, gospec.Equals, pool.UrlOf(key))
+			counts[connection.Url]++
+			pool.Push(connection)
+		}
+		c.Expect(len(counts), gospec.Equals, 2)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Commands wait for a connection to the key's Url", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:11391", "127.0.0.1:11392"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Borrow the key's only connection
+		key := "bob"
+		connection, err := pool.PopKey(key)
+		c.Expect(err, gospec.Equals, nil)
+
+		_, err = pool.PopKey(key)
+		c.Expect(err, gospec.Equals, ErrNoConnectionsAvailable)
+
+		// Return the connection after a short delay
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			pool.Push(connection)
+		}()
+
+		waited, err := pool.PopKeyTimeout(key, time.Second)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(waited, gospec.Equals, connection)
+
+		// The commands wait at most Timeout
+		pool.Timeout = time.Duration(10) * time.Millisecond
+		c.Expect(pool.Delete(key), gospec.Equals, ErrPoolTimeout)
+
+		// And get the connection once it is Pushed
+		go func() {
+			time.Sleep(time.Duration(5) * time.Millisecond)
+			pool.Push(waited)
+		}()
+		err = pool.Delete(key)
+		c.Expect(err, gospec.Satisfies, nil != err && ErrPoolTimeout != err && ErrNoConnectionsAvailable != err)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Opening a Pool that gives a Url no connections has errors", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:11391", "127.0.0.1:11392"}, Logger: memcached_pool_logger}
+		defer pool.Close()
+
+		err := pool.Open()
+		c.Expect(err, gospec.Satisfies, err != nil)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+
+		pool = MemcachedConnectionPool{Mode: LAZY, Size: 10, MaxOpen: 1, Urls: []string{"127.0.0.1:11391", "127.0.0.1:11392"}, Logger: memcached_pool_logger}
+		err = pool.Open()
+		c.Expect(err, gospec.Satisfies, err != nil)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[MemcachedConnectionPool] Keys don't fall back to the other Urls when their Url's breaker is open", func() {
+		pool := MemcachedConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:11391", "127.0.0.1:11392"}, Logger: memcached_pool_logger, BreakerThreshold: 1, BreakerCoolDown: time.Hour}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		key := "bob"
+		_, err := pool.Get(key)
+		c.Expect(err, gospec.Not(gospec.Equals), nil)
+		c.Expect(pool.CircuitStates()[pool.UrlOf(key)], gospec.Equals, CIRCUIT_OPEN)
+
+		_, err = pool.Get(key)
+		c.Expect(err, gospec.Equals, ErrCircuitOpen)
+		c.Expect(pool.Set(&memcached.Item{Key: key, Value: []byte("x")}), gospec.Equals, ErrCircuitOpen)
+		_, err = pool.GetMulti([]string{key})
+		c.Expect(err, gospec.Equals, ErrCircuitOpen)
+	})
 }
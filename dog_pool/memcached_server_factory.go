@@ -2,21 +2,28 @@ package dog_pool
 
 import "fmt"
 import "os/exec"
-import "errors"
 import "time"
-import "github.com/alecthomas/log4go"
 
 type MemcachedServerProcess struct {
 	port       int
-	logger     *log4go.Logger
+	logger     Logger
 	connection *MemcachedConnection
 	cmd        *exec.Cmd
 }
 
-func StartMemcachedServer(logger *log4go.Logger) (*MemcachedServerProcess, error) {
+func StartMemcachedServer(logger Logger) (*MemcachedServerProcess, error) {
+	return startMemcachedServer(logger, func(port int) ([]string, error) {
+		return []string{"-p", fmt.Sprintf("%d", port)}, nil
+	})
+}
+
+//
+// Start a memcached, with the arguments for the port it listens on
+//
+func startMemcachedServer(logger Logger, args func(port int) ([]string, error)) (*MemcachedServerProcess, error) {
 	var err error
 	if nil == logger {
-		return nil, errors.New("Nil logger")
+		logger = NopLogger{}
 	}
 
 	server := &MemcachedServerProcess{}
@@ -27,7 +34,11 @@ func StartMemcachedServer(logger *log4go.Logger) (*MemcachedServerProcess, error
 	}
 
 	// Start the server ...
-	server.cmd = exec.Command("memcached", "-p", fmt.Sprintf("%d", server.port))
+	server_args, err := args(server.port)
+	if nil != err {
+		return nil, err
+	}
+	server.cmd = exec.Command("memcached", server_args...)
 	err = server.cmd.Start()
 	if nil != err {
 		return nil, err
@@ -0,0 +1,775 @@
+//
+// Type-safe Object Pool written in GO.
+//
+
+package dog_pool
+
+import "context"
+import "errors"
+import "fmt"
+import "math/rand"
+import "sync"
+import "time"
+
+//
+// Pool of objects created by a typed factory
+//
+// Objects are tracked by identity while they are borrowed,
+// so T is normally a pointer or a handle type.
+//
+// When MaxOpen is set the pool is elastic: Open creates MinIdle objects,
+// Get creates more on demand up to MaxOpen, and the background checks close
+// the objects beyond MinIdle that are idle longer than MaxIdleTime.
+//
+type Pool[T comparable] struct {
+	Size     int               "(Max) Number of objects in the pool"
+	Factory  func() (T, error) "Creates a new object for the pool"
+	Validate func(T) error     "(optional) Checks an idle object before it is handed out"
+	Destroy  func(T)           "(optional) Releases an object removed from the pool"
+
+	HealthCheck         func(T) error "(optional) Checks the idle objects in the background"
+	HealthCheckInterval time.Duration "(optional) How often to check the idle objects, 0 disables the checks"
+	MaxIdleTime         time.Duration "(optional) Replace objects idle longer than this, checked every HealthCheckInterval or MaxIdleTime/2, 0 disables the limit"
+
+	MaxLifetime       time.Duration "(optional) Replace objects older than this when they are Get'd or Release'd, 0 disables the limit"
+	MaxLifetimeJitter time.Duration "(optional) Expire objects up to this much before MaxLifetime, defaults to 10% of MaxLifetime"
+
+	MinIdle int "(optional) Number of idle objects to keep open when MaxOpen is set"
+	MaxOpen int "(optional) Grow the pool on demand up to this many objects, 0 opens Size objects up front"
+
+	LeakThreshold time.Duration           "(optional) Report objects borrowed longer than this, 0 disables leak detection"
+	OnLeak        func(BorrowedObject[T]) "(optional) Called once for each object borrowed longer than LeakThreshold"
+
+	mutex    sync.Mutex          "Guards the channels and the borrowed objects"
+	slots    chan struct{}       "One token per borrowed object"
+	idle     chan *poolEntry[T]  "Buffered Channel of idle objects"
+	done     chan struct{}       "Closed when the pool is closed"
+	borrowed map[T]*poolEntry[T] "Objects handed out by the pool"
+	drained  chan struct{}       "Closed when the last borrowed object is Released after Close"
+	checking chan struct{}       "Closed when the health check holding a slot is done, nil if there is none"
+	waiting  int                 "Number of Gets waiting for the health check's slot"
+	counters poolCounters        "Counters for the Stats() snapshot"
+}
+
+//
+// Object in the pool
+//
+type poolEntry[T comparable] struct {
+	value     T
+	expiresAt time.Time "When the object exceeds MaxLifetime, zero if there is no limit"
+	idleAt    time.Time "When the object was returned to the pool"
+
+	borrowedAt time.Time "When the object was borrowed from the pool"
+	callers    []uintptr "Stack that borrowed the object, when LeakThreshold is set"
+	leaked     bool      "Has the object been reported as leaked?"
+}
+
+//
+// Has the object exceeded its lifetime?
+//
+func (e *poolEntry[T]) isExpired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+func (p *Pool[T]) String() string {
+	return fmt.Sprintf("Pool[%T] { Size=%v, Len=%v, InUse=%v }", *new(T), p.Size, p.Len(), p.InUse())
+}
+
+//
+// Is the pool open?
+//
+func (p *Pool[T]) IsOpen() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return nil != p.idle
+}
+
+//
+// Is the pool closed?
+//
+func (p *Pool[T]) IsClosed() bool {
+	return !p.IsOpen()
+}
+
+//
+// Number of idle objects in the pool
+// Returns -1 if the pool is not open
+//
+func (p *Pool[T]) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if nil == p.idle {
+		return -1
+	}
+	return len(p.idle)
+}
+
+//
+// Number of objects borrowed from the pool
+//
+func (p *Pool[T]) InUse() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return len(p.borrowed)
+}
+
+//
+// Snapshot of the pool's size and counters
+//
+func (p *Pool[T]) Stats() PoolStats {
+	p.mutex.Lock()
+	stats := PoolStats{Size: p.maxOpen(), Idle: len(p.idle), InUse: len(p.borrowed)}
+	p.mutex.Unlock()
+
+	stats.PopSuccesses = p.counters.pops.Load()
+	stats.PopFailures = p.counters.popFailures.Load()
+	stats.Waits = p.counters.waits.Load()
+	stats.WaitTime = time.Duration(p.counters.waitTime.Load())
+	return stats
+}
+
+//
+// Open the pool and fill it with Size objects, or MinIdle objects if MaxOpen is set
+//
+func (p *Pool[T]) Open() error {
+	p.Close()
+
+	switch {
+	case nil == p.Factory:
+		return errors.New("[Pool][Open] Nil Factory!")
+	case p.Size < 0:
+		return fmt.Errorf("[Pool][Open] Size[%v] must be >= 0!", p.Size)
+	case p.MaxOpen < 0:
+		return fmt.Errorf("[Pool][Open] MaxOpen[%v] must be >= 0!", p.MaxOpen)
+	case p.MinIdle < 0 || p.MinIdle > p.maxOpen():
+		return fmt.Errorf("[Pool][Open] MinIdle[%v] must be >= 0 and <= MaxOpen[%v]!", p.MinIdle, p.maxOpen())
+	}
+
+	// Fill the pool with objects
+	idle := make(chan *poolEntry[T], p.maxOpen())
+	for x := 0; x < p.minIdle(); x++ {
+		obj, err := p.Factory()
+
+		// Abort on errors, and release the objects we already created
+		if nil != err {
+			close(idle)
+			for entry := range idle {
+				p.destroy(entry.value)
+			}
+			return err
+		}
+
+		idle <- p.newEntry(obj)
+	}
+
+	done := make(chan struct{})
+
+	p.mutex.Lock()
+	p.slots = make(chan struct{}, p.maxOpen())
+	p.idle = idle
+	p.done = done
+	p.borrowed = make(map[T]*poolEntry[T])
+	p.drained = nil
+	p.checking = nil
+	p.mutex.Unlock()
+
+	// Check the idle objects in the background
+	if interval := p.checkInterval(); interval > 0 {
+		go p.runEvery(interval, done, p.checkIdle)
+	}
+
+	// Report the objects that are borrowed too long
+	if p.LeakThreshold > 0 {
+		go p.runEvery(p.LeakThreshold, done, p.checkLeaks)
+	}
+
+	return nil
+}
+
+//
+// Close the pool and destroy the idle objects
+//
+// Borrowed objects are destroyed when they are Released.
+//
+func (p *Pool[T]) Close() {
+	p.mutex.Lock()
+	idle, done := p.idle, p.done
+	p.slots = nil
+	p.idle = nil
+	p.done = nil
+
+	// Track the borrowed objects until they are Released
+	if nil != idle {
+		p.drained = make(chan struct{})
+		if 0 == len(p.borrowed) {
+			close(p.drained)
+		}
+	}
+	p.mutex.Unlock()
+
+	if nil == idle {
+		return
+	}
+
+	// Wake up any callers waiting for an object
+	close(done)
+
+	for {
+		select {
+		case entry := <-idle:
+			p.destroy(entry.value)
+		default:
+			return
+		}
+	}
+}
+
+//
+// Close the pool, and wait for the borrowed objects to be Released and destroyed
+//
+// Returns ErrPoolTimeout if the context's deadline expires first,
+// the remaining objects are destroyed when they are Released.
+//
+func (p *Pool[T]) Shutdown(ctx context.Context) error {
+	p.Close()
+
+	p.mutex.Lock()
+	drained := p.drained
+	p.mutex.Unlock()
+
+	// The pool was never opened
+	if nil == drained {
+		return nil
+	}
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+}
+
+//
+// Get an object from the pool without waiting for the borrowed objects
+// Returns ErrNoConnectionsAvailable if all the objects are borrowed
+//
+func (p *Pool[T]) Get() (T, error) {
+	return p.get(context.Background(), false)
+}
+
+//
+// Get an object from the pool, waiting for one to be returned
+//
+// Waiting callers are served in the order they started waiting.
+// Returns ErrPoolTimeout if the context's deadline expires first.
+//
+func (p *Pool[T]) GetContext(ctx context.Context) (T, error) {
+	return p.get(ctx, true)
+}
+
+//
+// Get an object from the pool, waiting at most timeout for one to be returned
+//
+func (p *Pool[T]) GetTimeout(timeout time.Duration) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return p.GetContext(ctx)
+}
+
+//
+// Return a borrowed object to the pool
+//
+// Objects returned to a closed pool are destroyed,
+// objects that are not borrowed from the pool are ignored.
+//
+func (p *Pool[T]) Release(obj T) {
+	p.release(obj, false)
+}
+
+//
+// Destroy a borrowed object instead of returning it to the pool
+//
+// The next Get will create a new object in its place.
+//
+func (p *Pool[T]) Discard(obj T) {
+	p.release(obj, true)
+}
+
+func (p *Pool[T]) get(ctx context.Context, wait bool) (obj T, err error) {
+	var zero T
+	defer func() { p.counters.addPop(err) }()
+
+	slots, idle, done := p.channels()
+	if nil == slots {
+		return zero, ErrConnectionIsClosed
+	}
+
+	// Reserve a slot for the object
+	select {
+	case slots <- struct{}{}:
+	default:
+		if !wait {
+			// Only the borrowed objects make the pool full, so wait out the health check
+			if err := p.waitForCheck(slots, done); nil != err {
+				return zero, err
+			}
+			break
+		}
+
+		// Wait for a slot, counting the time spent waiting
+		started := time.Now()
+		select {
+		case slots <- struct{}{}:
+			p.counters.addWait(started)
+		case <-done:
+			p.counters.addWait(started)
+			return zero, ErrConnectionIsClosed
+		case <-ctx.Done():
+			p.counters.addWait(started)
+			return zero, contextError(ctx)
+		}
+	}
+
+	return p.take(slots, idle)
+}
+
+//
+// Wait for the health check to free its slot, and reserve the slot
+// Returns ErrNoConnectionsAvailable if there is no health check, or the slot is taken first
+//
+func (p *Pool[T]) waitForCheck(slots chan struct{}, done chan struct{}) error {
+	p.mutex.Lock()
+	checking := p.checking
+	if nil == checking || slots != p.slots {
+		p.mutex.Unlock()
+		return ErrNoConnectionsAvailable
+	}
+	p.waiting++
+	p.mutex.Unlock()
+
+	defer func() {
+		p.mutex.Lock()
+		p.waiting--
+		p.mutex.Unlock()
+	}()
+
+	select {
+	case <-checking:
+	case <-done:
+		return ErrConnectionIsClosed
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+		return ErrNoConnectionsAvailable
+	}
+}
+
+//
+// Channels of the open pool, nil if the pool is closed
+//
+func (p *Pool[T]) channels() (slots chan struct{}, idle chan *poolEntry[T], done chan struct{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.slots, p.idle, p.done
+}
+
+//
+// Take an idle object, or create a new one, for the slot the caller reserved
+//
+func (p *Pool[T]) take(slots chan struct{}, idle chan *poolEntry[T]) (T, error) {
+	for {
+		select {
+		case entry := <-idle:
+			// Replace objects that are too old
+			if entry.isExpired(time.Now()) {
+				p.destroy(entry.value)
+				continue
+			}
+
+			// Replace objects that are no longer valid
+			if nil != p.Validate {
+				if err := p.Validate(entry.value); nil != err {
+					p.destroy(entry.value)
+					continue
+				}
+			}
+
+			return p.borrow(entry, slots)
+
+		default:
+			// No idle objects, create a new one
+			obj, err := p.Factory()
+			if nil != err {
+				var zero T
+				p.unreserve(slots)
+				return zero, err
+			}
+
+			return p.borrow(p.newEntry(obj), slots)
+		}
+	}
+}
+
+func (p *Pool[T]) borrow(entry *poolEntry[T], slots chan struct{}) (T, error) {
+	// Record who is borrowing the object
+	var callers []uintptr
+	if p.LeakThreshold > 0 {
+		callers = borrowStack()
+	}
+
+	p.mutex.Lock()
+
+	// The pool was closed while we were getting the object
+	if slots != p.slots {
+		p.mutex.Unlock()
+		p.destroy(entry.value)
+
+		var zero T
+		return zero, ErrConnectionIsClosed
+	}
+
+	entry.borrowedAt = time.Now()
+	entry.callers = callers
+	entry.leaked = false
+
+	p.borrowed[entry.value] = entry
+	p.mutex.Unlock()
+
+	return entry.value, nil
+}
+
+func (p *Pool[T]) release(obj T, discard bool) {
+	p.mutex.Lock()
+
+	// The pool is closed, destroy the object
+	// and wake up Shutdown after the last borrowed object
+	if nil == p.idle {
+		_, ok := p.borrowed[obj]
+		delete(p.borrowed, obj)
+		last, drained := ok && 0 == len(p.borrowed), p.drained
+		p.mutex.Unlock()
+
+		p.destroy(obj)
+		if last {
+			close(drained)
+		}
+		return
+	}
+
+	// Not one of our objects
+	entry, ok := p.borrowed[obj]
+	if !ok {
+		p.mutex.Unlock()
+		return
+	}
+	delete(p.borrowed, obj)
+	slots := p.slots
+	p.mutex.Unlock()
+
+	if discard {
+		p.destroy(obj)
+		p.unreserve(slots)
+		return
+	}
+
+	// Replace objects that are too old
+	if entry.isExpired(time.Now()) {
+		p.replace(entry, slots)
+		return
+	}
+
+	entry.idleAt = time.Now()
+	p.restore(entry, slots)
+}
+
+//
+// Return an idle object to the pool and free its slot
+//
+func (p *Pool[T]) restore(entry *poolEntry[T], slots chan struct{}) {
+	p.mutex.Lock()
+
+	// Return the object while holding the lock, so Close() can't miss it
+	restored := false
+	if slots == p.slots {
+		select {
+		case p.idle <- entry:
+			restored = true
+		default:
+		}
+	}
+	p.mutex.Unlock()
+
+	if !restored {
+		p.destroy(entry.value)
+	}
+	p.unreserve(slots)
+}
+
+//
+// Free the slot for the next caller
+//
+func (p *Pool[T]) unreserve(slots chan struct{}) {
+	select {
+	case <-slots:
+	default:
+	}
+}
+
+//
+// Call fn every interval until the pool is closed
+//
+func (p *Pool[T]) runEvery(interval time.Duration, done chan struct{}, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+//
+// Check each of the idle objects once
+//
+func (p *Pool[T]) checkIdle() {
+	p.mutex.Lock()
+	slots, idle := p.slots, p.idle
+	p.mutex.Unlock()
+
+	if nil == idle {
+		return
+	}
+
+	for count := len(idle); count > 0; count-- {
+		// Reserve a slot, so the pool can't grow while we hold the object
+		if !p.reserveCheck(slots) {
+			return
+		}
+
+		select {
+		case entry := <-idle:
+			p.checkEntry(entry, slots)
+			p.finishCheck(slots)
+		default:
+			p.unreserve(slots)
+			p.finishCheck(slots)
+			return
+		}
+	}
+
+	// Open new objects to keep MinIdle objects idle, without exceeding MaxOpen
+	for count := p.missingIdle(); count > 0; count-- {
+		if !p.reserveCheck(slots) {
+			return
+		}
+
+		obj, err := p.Factory()
+		if nil != err {
+			p.unreserve(slots)
+			p.finishCheck(slots)
+			return
+		}
+		p.restore(p.newEntry(obj), slots)
+		p.finishCheck(slots)
+	}
+}
+
+//
+// Reserve a slot for the health check, unless a Get is waiting for one
+//
+func (p *Pool[T]) reserveCheck(slots chan struct{}) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if slots != p.slots || p.waiting > 0 {
+		return false
+	}
+
+	select {
+	case slots <- struct{}{}:
+		p.checking = make(chan struct{})
+		return true
+	default:
+		return false
+	}
+}
+
+//
+// Wake up the Gets waiting for the health check, after it freed its slot
+//
+func (p *Pool[T]) finishCheck(slots chan struct{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if slots == p.slots && nil != p.checking {
+		close(p.checking)
+		p.checking = nil
+	}
+}
+
+//
+// Replace the object if it has been idle too long or fails its health check,
+// closing it instead if the other idle objects are enough to keep MinIdle
+//
+func (p *Pool[T]) checkEntry(entry *poolEntry[T], slots chan struct{}) {
+	idled := p.MaxIdleTime > 0 && time.Since(entry.idleAt) > p.MaxIdleTime
+
+	// Shrink the pool back to MinIdle objects
+	if idled && p.isSurplus() {
+		p.destroy(entry.value)
+		p.unreserve(slots)
+		return
+	}
+
+	expired := idled || entry.isExpired(time.Now())
+	if !expired && (0 == p.HealthCheckInterval || nil == p.HealthCheck || nil == p.HealthCheck(entry.value)) {
+		p.restore(entry, slots)
+		return
+	}
+
+	p.replace(entry, slots)
+}
+
+//
+// Destroy the object and open a replacement, so the pool stays warm
+//
+// If that fails, the next Get will create the object instead.
+//
+func (p *Pool[T]) replace(entry *poolEntry[T], slots chan struct{}) {
+	p.destroy(entry.value)
+
+	obj, err := p.Factory()
+	if nil != err {
+		p.unreserve(slots)
+		return
+	}
+	if p.HealthCheckInterval > 0 && nil != p.HealthCheck {
+		if err := p.HealthCheck(obj); nil != err {
+			p.destroy(obj)
+			p.unreserve(slots)
+			return
+		}
+	}
+
+	p.restore(p.newEntry(obj), slots)
+}
+
+//
+// Wrap a new object, expiring it up to MaxLifetimeJitter early,
+// so the objects created together aren't all replaced together
+//
+func (p *Pool[T]) newEntry(obj T) *poolEntry[T] {
+	now := time.Now()
+	entry := &poolEntry[T]{value: obj, idleAt: now}
+
+	if p.MaxLifetime > 0 {
+		jitter := p.MaxLifetimeJitter
+		if 0 == jitter {
+			jitter = p.MaxLifetime / 10
+		}
+		if jitter > p.MaxLifetime {
+			jitter = p.MaxLifetime
+		}
+
+		lifetime := p.MaxLifetime
+		if jitter > 0 {
+			lifetime -= time.Duration(rand.Int63n(int64(jitter)))
+		}
+		entry.expiresAt = now.Add(lifetime)
+	}
+
+	return entry
+}
+
+//
+// How often to check the idle objects
+//
+// Without HealthCheckInterval the idle objects are still swept for MaxIdleTime,
+// twice per MaxIdleTime, but the HealthCheck isn't called.
+//
+func (p *Pool[T]) checkInterval() time.Duration {
+	switch {
+	case p.HealthCheckInterval > 0:
+		return p.HealthCheckInterval
+	case p.MaxIdleTime > 1:
+		return p.MaxIdleTime / 2
+	}
+	return p.MaxIdleTime
+}
+
+//
+// Number of objects to open to get back to MinIdle idle objects
+//
+func (p *Pool[T]) missingIdle() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if nil == p.idle {
+		return 0
+	}
+
+	idle, open := len(p.idle), len(p.idle)+len(p.borrowed)
+	if count := p.maxOpen() - open; count < p.minIdle()-idle {
+		return count
+	}
+	return p.minIdle() - idle
+}
+
+//
+// Can Get return an object without waiting?
+//
+func (p *Pool[T]) hasFreeSlot() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return nil != p.slots && len(p.slots) < cap(p.slots)
+}
+
+//
+// Are there at least MinIdle other idle objects?
+//
+func (p *Pool[T]) isSurplus() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return len(p.idle) >= p.minIdle()
+}
+
+//
+// Max number of open objects
+//
+func (p *Pool[T]) maxOpen() int {
+	if p.MaxOpen > 0 {
+		return p.MaxOpen
+	}
+	return p.Size
+}
+
+//
+// Number of objects to keep idle
+//
+func (p *Pool[T]) minIdle() int {
+	if p.MaxOpen > 0 {
+		return p.MinIdle
+	}
+	return p.Size
+}
+
+func (p *Pool[T]) destroy(obj T) {
+	if nil != p.Destroy {
+		p.Destroy(obj)
+	}
+}
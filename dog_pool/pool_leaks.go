@@ -0,0 +1,100 @@
+//
+// Leak detection for the Type-safe Object Pool
+//
+
+package dog_pool
+
+import "bytes"
+import "fmt"
+import "runtime"
+import "sort"
+import "time"
+
+//
+// Object borrowed from a Pool
+//
+type BorrowedObject[T comparable] struct {
+	Value      T         "Object borrowed from the pool"
+	BorrowedAt time.Time "When the object was borrowed"
+	Stack      string    "Stack that borrowed the object, empty unless LeakThreshold is set"
+}
+
+func (p BorrowedObject[T]) String() string {
+	return fmt.Sprintf("BorrowedObject { Value=%v, BorrowedAt=%v }", p.Value, p.BorrowedAt)
+}
+
+//
+// Objects that are currently borrowed from the pool, oldest first
+// Returns nil if the pool is not open
+//
+func (p *Pool[T]) Outstanding() []BorrowedObject[T] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if nil == p.idle {
+		return nil
+	}
+
+	output := make([]BorrowedObject[T], 0, len(p.borrowed))
+	for _, entry := range p.borrowed {
+		output = append(output, entry.borrowedObject())
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].BorrowedAt.Before(output[j].BorrowedAt) })
+	return output
+}
+
+//
+// Report each object borrowed longer than LeakThreshold once
+//
+func (p *Pool[T]) checkLeaks() {
+	now := time.Now()
+
+	p.mutex.Lock()
+	var leaks []BorrowedObject[T]
+	for _, entry := range p.borrowed {
+		if !entry.leaked && now.Sub(entry.borrowedAt) > p.LeakThreshold {
+			entry.leaked = true
+			leaks = append(leaks, entry.borrowedObject())
+		}
+	}
+	p.mutex.Unlock()
+
+	if nil == p.OnLeak {
+		return
+	}
+	for _, leak := range leaks {
+		p.OnLeak(leak)
+	}
+}
+
+func (e *poolEntry[T]) borrowedObject() BorrowedObject[T] {
+	return BorrowedObject[T]{Value: e.value, BorrowedAt: e.borrowedAt, Stack: formatStack(e.callers)}
+}
+
+//
+// Record the stack of the caller borrowing an object,
+// skipping runtime.Callers, borrowStack, Pool.borrow and Pool.get
+//
+func borrowStack() []uintptr {
+	callers := make([]uintptr, 32)
+	return callers[:runtime.Callers(4, callers)]
+}
+
+//
+// Format the stack like a panic, one "function\n\tfile:line" per frame
+//
+func formatStack(callers []uintptr) string {
+	if 0 == len(callers) {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	frames := runtime.CallersFrames(callers)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&buffer, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			return buffer.String()
+		}
+	}
+}
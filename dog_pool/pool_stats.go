@@ -0,0 +1,114 @@
+//
+// Connection Pool Statistics written in GO
+//
+
+package dog_pool
+
+import "fmt"
+import "sync/atomic"
+import "time"
+
+//
+// Snapshot of a pool's counters
+//
+type PoolStats struct {
+	Size  int "(Max) Number of connections in the pool"
+	Idle  int "Number of idle connections in the pool"
+	InUse int "Number of connections borrowed from the pool"
+
+	PopSuccesses uint64        "Number of Pop's that returned a connection"
+	PopFailures  uint64        "Number of Pop's that returned an error"
+	Waits        uint64        "Number of Pop's that had to wait for a connection"
+	WaitTime     time.Duration "Total time spent waiting for connections"
+
+	DialErrors  uint64 "Number of failed attempts to connect"
+	FatalErrors uint64 "Number of connections closed by fatal errors"
+}
+
+func (p PoolStats) String() string {
+	return fmt.Sprintf("PoolStats { Size=%v, Idle=%v, InUse=%v, PopSuccesses=%v, PopFailures=%v, Waits=%v, WaitTime=%v, DialErrors=%v, FatalErrors=%v }", p.Size, p.Idle, p.InUse, p.PopSuccesses, p.PopFailures, p.Waits, p.WaitTime, p.DialErrors, p.FatalErrors)
+}
+
+//
+// Add the sizes and counters of two snapshots together
+//
+func (p PoolStats) plus(other PoolStats) PoolStats {
+	p.Size += other.Size
+	p.Idle += other.Idle
+	p.InUse += other.InUse
+	p.PopSuccesses += other.PopSuccesses
+	p.PopFailures += other.PopFailures
+	p.Waits += other.Waits
+	p.WaitTime += other.WaitTime
+	p.DialErrors += other.DialErrors
+	p.FatalErrors += other.FatalErrors
+	return p
+}
+
+//
+// Counters for the objects handed out by a Pool
+//
+type poolCounters struct {
+	pops        atomic.Uint64
+	popFailures atomic.Uint64
+	waits       atomic.Uint64
+	waitTime    atomic.Int64
+}
+
+//
+// Count a Get/Pop
+//
+func (p *poolCounters) addPop(err error) {
+	if nil == err {
+		p.pops.Add(1)
+	} else {
+		p.popFailures.Add(1)
+	}
+}
+
+//
+// Count the time spent waiting for an object
+//
+func (p *poolCounters) addWait(started time.Time) {
+	p.waits.Add(1)
+	p.waitTime.Add(int64(time.Since(started)))
+}
+
+//
+// Counters shared by the connections in a pool
+//
+// A nil *connectionStats is valid, and doesn't count anything.
+//
+type connectionStats struct {
+	dialErrors  atomic.Uint64
+	fatalErrors atomic.Uint64
+}
+
+//
+// Count a failed attempt to connect
+//
+func (p *connectionStats) addDialError() {
+	if nil != p {
+		p.dialErrors.Add(1)
+	}
+}
+
+//
+// Count a connection closed by a fatal error
+//
+func (p *connectionStats) addFatalError() {
+	if nil != p {
+		p.fatalErrors.Add(1)
+	}
+}
+
+//
+// Add the connection counters to the snapshot
+//
+func (p *connectionStats) addTo(stats PoolStats) PoolStats {
+	if nil != p {
+		stats.DialErrors += p.dialErrors.Load()
+		stats.FatalErrors += p.fatalErrors.Load()
+	}
+	return stats
+}
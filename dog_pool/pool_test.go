@@ -0,0 +1,912 @@
+package dog_pool
+
+import "context"
+import "errors"
+import "strings"
+import "sync"
+import "sync/atomic"
+import "testing"
+import "time"
+
+//
+// Helper to build a pool of *stringWrapper's and count the destroyed objects
+//
+func makeStringWrapperPool(size int) (*Pool[*stringWrapper], *int) {
+	destroyed := 0
+	pool := &Pool[*stringWrapper]{
+		Size:    size,
+		Factory: func() (*stringWrapper, error) { return &stringWrapper{Value: "Hello"}, nil },
+		Destroy: func(*stringWrapper) { destroyed++ },
+	}
+	return pool, &destroyed
+}
+
+//
+// Pool: Open
+//
+
+func Test_Pool_Open_1(t *testing.T) {
+	tag := "Open - Fills the pool"
+
+	pool, _ := makeStringWrapperPool(3)
+	if err := pool.Open(); nil != err {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, nil, err)
+		return
+	}
+	defer pool.Close()
+
+	if pool.IsClosed() || pool.Len() != 3 || pool.InUse() != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 3, pool.Len())
+		return
+	}
+}
+
+func Test_Pool_Open_2(t *testing.T) {
+	tag := "Open - Factory error destroys the created objects"
+
+	expected := errors.New("Factory Error")
+
+	count := 0
+	destroyed := 0
+	pool := &Pool[*stringWrapper]{
+		Size: 3,
+		Factory: func() (*stringWrapper, error) {
+			if count++; count == 3 {
+				return nil, expected
+			}
+			return &stringWrapper{Value: "Hello"}, nil
+		},
+		Destroy: func(*stringWrapper) { destroyed++ },
+	}
+
+	if err := pool.Open(); err != expected {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, expected, err)
+		return
+	}
+
+	if pool.IsOpen() || pool.Len() != -1 || destroyed != 2 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 2, destroyed)
+		return
+	}
+}
+
+func Test_Pool_Open_3(t *testing.T) {
+	tag := "Open - Nil Factory has errors"
+
+	pool := &Pool[*stringWrapper]{Size: 1}
+	if err := pool.Open(); nil == err {
+		t.Errorf("[%s] Expected=error, Actual=%#v", tag, err)
+		return
+	}
+}
+
+//
+// Pool: Get
+//
+
+func Test_Pool_Get_1(t *testing.T) {
+	tag := "Get - Empty Pool returns ErrNoConnectionsAvailable"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.Open()
+	defer pool.Close()
+
+	// Pool contains 1 object
+	if c, err := pool.Get(); nil != err || nil == c || c.Value != "Hello" {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, "Hello", c, err)
+		return
+	}
+
+	// Pool contains 0 objects
+	if c, err := pool.Get(); err != ErrNoConnectionsAvailable || nil != c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrNoConnectionsAvailable, c, err)
+		return
+	}
+}
+
+func Test_Pool_Get_2(t *testing.T) {
+	tag := "Get - Closed Pool returns ErrConnectionIsClosed"
+
+	pool, _ := makeStringWrapperPool(1)
+
+	if c, err := pool.Get(); err != ErrConnectionIsClosed || nil != c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrConnectionIsClosed, c, err)
+		return
+	}
+}
+
+func Test_Pool_Get_3(t *testing.T) {
+	tag := "Get - Invalid objects are destroyed and replaced"
+
+	pool, destroyed := makeStringWrapperPool(1)
+	pool.Validate = func(c *stringWrapper) error {
+		if c.Value != "Hello" {
+			return errors.New("Invalid")
+		}
+		return nil
+	}
+	pool.Open()
+	defer pool.Close()
+
+	// Invalidate the object
+	c, _ := pool.Get()
+	c.Value = "Goodbye"
+	pool.Release(c)
+
+	// Get a new object
+	if c2, err := pool.Get(); nil != err || c2 == c || c2.Value != "Hello" || *destroyed != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, "Hello", c2, err)
+		return
+	}
+}
+
+//
+// Pool: GetTimeout/GetContext
+//
+
+func Test_Pool_GetTimeout_1(t *testing.T) {
+	tag := "GetTimeout - Empty Pool, Timeout Error"
+
+	pool, _ := makeStringWrapperPool(0)
+	pool.Open()
+	defer pool.Close()
+
+	if c, err := pool.GetTimeout(time.Millisecond * 10); err != ErrPoolTimeout || nil != c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrPoolTimeout, c, err)
+		return
+	}
+}
+
+func Test_Pool_GetTimeout_2(t *testing.T) {
+	tag := "GetTimeout - Waits for Released Object"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+
+	// Release the object after a short delay
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		pool.Release(c)
+	}()
+
+	if c2, err := pool.GetTimeout(time.Second); nil != err || c2 != c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, c, c2, err)
+		return
+	}
+}
+
+func Test_Pool_GetContext_1(t *testing.T) {
+	tag := "GetContext - Closing the pool wakes up waiting callers"
+
+	pool, _ := makeStringWrapperPool(0)
+	pool.Open()
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		pool.Close()
+	}()
+
+	if c, err := pool.GetContext(context.Background()); err != ErrConnectionIsClosed || nil != c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrConnectionIsClosed, c, err)
+		return
+	}
+}
+
+//
+// Pool: Release/Discard
+//
+
+func Test_Pool_Release_1(t *testing.T) {
+	tag := "Release - Returns the object to the pool"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	if pool.Len() != 0 || pool.InUse() != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, pool.InUse())
+		return
+	}
+
+	pool.Release(c)
+	if pool.Len() != 1 || pool.InUse() != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, pool.Len())
+		return
+	}
+}
+
+func Test_Pool_Release_2(t *testing.T) {
+	tag := "Release - Ignores objects not borrowed from the pool"
+
+	pool, destroyed := makeStringWrapperPool(1)
+	pool.Open()
+	defer pool.Close()
+
+	pool.Release(&stringWrapper{Value: "Stranger"})
+	if pool.Len() != 1 || *destroyed != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, pool.Len())
+		return
+	}
+}
+
+func Test_Pool_Release_3(t *testing.T) {
+	tag := "Release - Destroys objects returned to a closed pool"
+
+	pool, destroyed := makeStringWrapperPool(1)
+	pool.Open()
+
+	c, _ := pool.Get()
+	pool.Close()
+
+	pool.Release(c)
+	if *destroyed != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, *destroyed)
+		return
+	}
+}
+
+func Test_Pool_Discard_1(t *testing.T) {
+	tag := "Discard - Destroys the object and frees the slot"
+
+	pool, destroyed := makeStringWrapperPool(1)
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	pool.Discard(c)
+	if *destroyed != 1 || pool.Len() != 0 || pool.InUse() != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, *destroyed)
+		return
+	}
+
+	// A new object is created in its place
+	if c2, err := pool.Get(); nil != err || c2 == c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, "New Object", c2, err)
+		return
+	}
+}
+
+//
+// Pool: Close
+//
+
+func Test_Pool_Close_1(t *testing.T) {
+	tag := "Close - Destroys the idle objects"
+
+	pool, destroyed := makeStringWrapperPool(3)
+	pool.Open()
+	pool.Close()
+
+	if pool.IsOpen() || *destroyed != 3 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 3, *destroyed)
+		return
+	}
+}
+
+//
+// Pool: HealthCheck
+//
+
+func Test_Pool_HealthCheck_1(t *testing.T) {
+	tag := "HealthCheck - Failing idle objects are replaced"
+
+	pool, _ := makeStringWrapperPool(2)
+	pool.HealthCheckInterval = time.Millisecond * 5
+
+	destroyed := make(chan *stringWrapper, 10)
+	pool.Destroy = func(c *stringWrapper) { destroyed <- c }
+	pool.HealthCheck = func(c *stringWrapper) error {
+		if c.Value != "Hello" {
+			return errors.New("Unhealthy")
+		}
+		return nil
+	}
+	pool.Open()
+	defer pool.Close()
+
+	// Break one of the objects
+	c, _ := pool.Get()
+	c.Value = "Goodbye"
+	pool.Release(c)
+
+	select {
+	case obj := <-destroyed:
+		if obj != c {
+			t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, c, obj)
+			return
+		}
+	case <-time.After(time.Second):
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, c, nil)
+		return
+	}
+
+	// The pool is still full
+	time.Sleep(time.Millisecond * 10)
+	if pool.Len() != 2 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 2, pool.Len())
+		return
+	}
+}
+
+func Test_Pool_HealthCheck_2(t *testing.T) {
+	tag := "HealthCheck - Objects idle longer than MaxIdleTime are replaced"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.HealthCheckInterval = time.Millisecond * 5
+	pool.MaxIdleTime = time.Millisecond * 20
+
+	destroyed := make(chan *stringWrapper, 10)
+	pool.Destroy = func(c *stringWrapper) { destroyed <- c }
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	pool.Release(c)
+
+	select {
+	case obj := <-destroyed:
+		if obj != c {
+			t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, c, obj)
+			return
+		}
+	case <-time.After(time.Second):
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, c, nil)
+		return
+	}
+
+	// The replacement is a new object
+	time.Sleep(time.Millisecond * 10)
+	if c2, err := pool.Get(); nil != err || c2 == c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, "New Object", c2, err)
+		return
+	}
+}
+
+func Test_Pool_HealthCheck_3(t *testing.T) {
+	tag := "HealthCheck - Borrowed objects are not checked"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.HealthCheckInterval = time.Millisecond * 5
+
+	checked := make(chan *stringWrapper, 10)
+	pool.HealthCheck = func(c *stringWrapper) error {
+		checked <- c
+		return nil
+	}
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	defer pool.Release(c)
+
+	select {
+	case obj := <-checked:
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, nil, obj)
+	case <-time.After(time.Millisecond * 30):
+	}
+}
+
+func Test_Pool_HealthCheck_4(t *testing.T) {
+	tag := "HealthCheck - Get waits for the object being checked instead of failing"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.HealthCheckInterval = time.Millisecond * 5
+
+	checking := make(chan *stringWrapper, 1)
+	pool.HealthCheck = func(c *stringWrapper) error {
+		select {
+		case checking <- c:
+		default:
+		}
+		time.Sleep(time.Millisecond * 50)
+		return nil
+	}
+	pool.Open()
+	defer pool.Close()
+
+	var checked *stringWrapper
+	select {
+	case checked = <-checking:
+	case <-time.After(time.Second):
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, "Health Check", nil)
+		return
+	}
+
+	c, err := pool.Get()
+	if nil != err || c != checked {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, checked, c, err)
+		return
+	}
+	pool.Release(c)
+}
+
+//
+// Pool: MaxLifetime
+//
+
+func Test_Pool_MaxLifetime_1(t *testing.T) {
+	tag := "MaxLifetime - Expired objects are replaced when they are Released"
+
+	pool, destroyed := makeStringWrapperPool(1)
+	pool.MaxLifetime = time.Millisecond * 10
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	time.Sleep(time.Millisecond * 20)
+	pool.Release(c)
+
+	if *destroyed != 1 || pool.Len() != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, *destroyed)
+		return
+	}
+
+	if c2, err := pool.Get(); nil != err || c2 == c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, "New Object", c2, err)
+		return
+	}
+}
+
+func Test_Pool_MaxLifetime_2(t *testing.T) {
+	tag := "MaxLifetime - Expired idle objects are replaced when they are Get'd"
+
+	pool, destroyed := makeStringWrapperPool(1)
+	pool.MaxLifetime = time.Millisecond * 10
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	pool.Release(c)
+	time.Sleep(time.Millisecond * 20)
+
+	if c2, err := pool.Get(); nil != err || c2 == c || *destroyed != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, "New Object", c2, err)
+		return
+	}
+}
+
+func Test_Pool_MaxLifetime_3(t *testing.T) {
+	tag := "MaxLifetime - Jitter staggers the expiration"
+
+	pool, _ := makeStringWrapperPool(0)
+	pool.MaxLifetime = time.Hour
+	pool.MaxLifetimeJitter = time.Minute * 30
+
+	now := time.Now()
+	expires := make(map[time.Time]bool)
+	for i := 0; i < 10; i++ {
+		entry := pool.newEntry(&stringWrapper{})
+		if entry.expiresAt.Before(now.Add(time.Minute*30)) || entry.expiresAt.After(time.Now().Add(time.Hour)) {
+			t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, "30m to 1h", entry.expiresAt.Sub(now))
+			return
+		}
+		expires[entry.expiresAt] = true
+	}
+
+	if len(expires) < 2 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, "> 1", len(expires))
+		return
+	}
+}
+
+//
+// Pool: Stats
+//
+
+func Test_Pool_Stats_1(t *testing.T) {
+	tag := "Stats - Counts the idle and borrowed objects"
+
+	pool, _ := makeStringWrapperPool(3)
+	if stats := pool.Stats(); stats != (PoolStats{Size: 3}) {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, PoolStats{Size: 3}, stats)
+		return
+	}
+
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	pool.Get()
+	pool.Release(c)
+
+	expected := PoolStats{Size: 3, Idle: 2, InUse: 1, PopSuccesses: 2}
+	if stats := pool.Stats(); stats != expected {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, expected, stats)
+		return
+	}
+}
+
+func Test_Pool_Stats_2(t *testing.T) {
+	tag := "Stats - Counts the failures and the time spent waiting"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	pool.Get()
+	pool.GetTimeout(time.Millisecond * 10)
+
+	// Release the object after a short delay
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		pool.Release(c)
+	}()
+	pool.GetTimeout(time.Second)
+
+	stats := pool.Stats()
+	if stats.PopSuccesses != 2 || stats.PopFailures != 2 || stats.Waits != 2 || stats.WaitTime < time.Millisecond*20 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, "2 successes, 2 failures, 2 waits >= 20ms", stats)
+		return
+	}
+}
+
+func Test_Pool_Stats_3(t *testing.T) {
+	tag := "Stats - Counts the Factory errors as failures"
+
+	count := 0
+	pool := &Pool[*stringWrapper]{
+		Size: 1,
+		Factory: func() (*stringWrapper, error) {
+			if count++; count > 1 {
+				return nil, errors.New("Factory Error")
+			}
+			return &stringWrapper{Value: "Hello"}, nil
+		},
+	}
+	pool.Open()
+	defer pool.Close()
+
+	// Discard the only object, so the next Get calls the Factory
+	c, _ := pool.Get()
+	pool.Discard(c)
+	pool.Get()
+
+	expected := PoolStats{Size: 1, PopSuccesses: 1, PopFailures: 1}
+	if stats := pool.Stats(); stats != expected {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, expected, stats)
+		return
+	}
+}
+
+//
+// Pool: MinIdle/MaxOpen
+//
+
+func Test_Pool_MaxOpen_1(t *testing.T) {
+	tag := "MaxOpen - Opens MinIdle objects, and grows on demand up to MaxOpen"
+
+	pool, _ := makeStringWrapperPool(10)
+	pool.MinIdle = 1
+	pool.MaxOpen = 3
+	pool.Open()
+	defer pool.Close()
+
+	if pool.Len() != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, pool.Len())
+		return
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := pool.Get(); nil != err {
+			t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, nil, err)
+			return
+		}
+	}
+
+	if c, err := pool.Get(); err != ErrNoConnectionsAvailable || nil != c {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrNoConnectionsAvailable, c, err)
+		return
+	}
+
+	if stats := pool.Stats(); stats.Size != 3 || stats.InUse != 3 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, "Size=3, InUse=3", stats)
+		return
+	}
+}
+
+func Test_Pool_MaxOpen_2(t *testing.T) {
+	tag := "MaxOpen - Idle objects beyond MinIdle are closed after MaxIdleTime"
+
+	pool, _ := makeStringWrapperPool(0)
+	pool.MinIdle = 1
+	pool.MaxOpen = 3
+	pool.HealthCheckInterval = time.Millisecond * 5
+	pool.MaxIdleTime = time.Millisecond * 20
+	pool.Open()
+	defer pool.Close()
+
+	// Grow the pool to MaxOpen, then return the objects
+	c1, _ := pool.Get()
+	c2, _ := pool.Get()
+	c3, _ := pool.Get()
+	pool.Release(c1)
+	pool.Release(c2)
+	pool.Release(c3)
+
+	for i := 0; i < 100 && pool.Len() > 1; i++ {
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	if pool.Len() != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, pool.Len())
+		return
+	}
+}
+
+func Test_Pool_MaxOpen_3(t *testing.T) {
+	tag := "MaxOpen - Health checks open objects to keep MinIdle idle"
+
+	pool, _ := makeStringWrapperPool(0)
+	pool.MinIdle = 2
+	pool.MaxOpen = 3
+	pool.HealthCheckInterval = time.Millisecond * 5
+	pool.Open()
+	defer pool.Close()
+
+	// Borrow two objects, leaving no idle objects
+	pool.Get()
+	pool.Get()
+
+	// Only one more object fits under MaxOpen
+	time.Sleep(time.Millisecond * 30)
+	if pool.Len() != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, pool.Len())
+		return
+	}
+}
+
+func Test_Pool_MaxOpen_4(t *testing.T) {
+	tag := "MaxOpen - MinIdle larger than MaxOpen has errors"
+
+	pool, _ := makeStringWrapperPool(0)
+	pool.MinIdle = 4
+	pool.MaxOpen = 3
+	if err := pool.Open(); nil == err {
+		t.Errorf("[%s] Expected=error, Actual=%#v", tag, err)
+		return
+	}
+}
+
+func Test_Pool_MaxOpen_5(t *testing.T) {
+	tag := "MaxOpen - Idle objects beyond MinIdle are closed after MaxIdleTime without the health checks"
+
+	pool, _ := makeStringWrapperPool(0)
+	pool.MinIdle = 1
+	pool.MaxOpen = 3
+	pool.MaxIdleTime = time.Millisecond * 20
+
+	checked := make(chan *stringWrapper, 10)
+	pool.HealthCheck = func(c *stringWrapper) error { checked <- c; return nil }
+	pool.Open()
+	defer pool.Close()
+
+	// Grow the pool to MaxOpen, then return the objects
+	c1, _ := pool.Get()
+	c2, _ := pool.Get()
+	c3, _ := pool.Get()
+	pool.Release(c1)
+	pool.Release(c2)
+	pool.Release(c3)
+
+	for i := 0; i < 100 && pool.Len() > 1; i++ {
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	if pool.Len() != 1 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, pool.Len())
+		return
+	}
+	if len(checked) != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 0, len(checked))
+		return
+	}
+}
+
+//
+// Pool: Outstanding/LeakThreshold
+//
+
+func Test_Pool_Outstanding_1(t *testing.T) {
+	tag := "Outstanding - Lists the borrowed objects, oldest first"
+
+	pool, _ := makeStringWrapperPool(3)
+	if nil != pool.Outstanding() {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, nil, pool.Outstanding())
+		return
+	}
+
+	pool.Open()
+	defer pool.Close()
+
+	c1, _ := pool.Get()
+	time.Sleep(time.Millisecond)
+	c2, _ := pool.Get()
+
+	outstanding := pool.Outstanding()
+	if len(outstanding) != 2 || outstanding[0].Value != c1 || outstanding[1].Value != c2 || "" != outstanding[0].Stack {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, []*stringWrapper{c1, c2}, outstanding)
+		return
+	}
+
+	pool.Release(c1)
+	if outstanding := pool.Outstanding(); len(outstanding) != 1 || outstanding[0].Value != c2 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, []*stringWrapper{c2}, outstanding)
+		return
+	}
+}
+
+func Test_Pool_LeakThreshold_1(t *testing.T) {
+	tag := "LeakThreshold - Reports objects borrowed too long once, with the borrowing stack"
+
+	pool, _ := makeStringWrapperPool(1)
+	pool.LeakThreshold = time.Millisecond * 5
+
+	leaks := make(chan BorrowedObject[*stringWrapper], 10)
+	pool.OnLeak = func(b BorrowedObject[*stringWrapper]) { leaks <- b }
+	pool.Open()
+	defer pool.Close()
+
+	c, _ := pool.Get()
+	defer pool.Release(c)
+
+	select {
+	case leak := <-leaks:
+		if leak.Value != c || !strings.Contains(leak.Stack, "Test_Pool_LeakThreshold_1") {
+			t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, c, leak)
+			return
+		}
+	case <-time.After(time.Second):
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, c, nil)
+		return
+	}
+
+	select {
+	case leak := <-leaks:
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, nil, leak)
+	case <-time.After(time.Millisecond * 30):
+	}
+}
+
+//
+// Pool: Shutdown
+//
+
+func Test_Pool_Shutdown_1(t *testing.T) {
+	tag := "Shutdown - Waits for the borrowed objects to be Released"
+
+	pool, _ := makeStringWrapperPool(2)
+	destroyed := make(chan *stringWrapper, 10)
+	pool.Destroy = func(c *stringWrapper) { destroyed <- c }
+	pool.Open()
+
+	c, _ := pool.Get()
+
+	// Release the object after a short delay
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		pool.Release(c)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := pool.Shutdown(ctx); nil != err || len(destroyed) != 2 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, 2, len(destroyed), err)
+		return
+	}
+}
+
+func Test_Pool_Shutdown_2(t *testing.T) {
+	tag := "Shutdown - Times out, and destroys the objects Released later"
+
+	pool, destroyed := makeStringWrapperPool(1)
+	pool.Open()
+
+	c, _ := pool.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	if err := pool.Shutdown(ctx); err != ErrPoolTimeout || *destroyed != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrPoolTimeout, *destroyed, err)
+		return
+	}
+
+	pool.Release(c)
+	if *destroyed != 1 || pool.InUse() != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, 1, *destroyed)
+		return
+	}
+}
+
+func Test_Pool_Shutdown_3(t *testing.T) {
+	tag := "Shutdown - Stops new borrows"
+
+	pool, _ := makeStringWrapperPool(2)
+	pool.Open()
+
+	c, _ := pool.Get()
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+
+		if c2, err := pool.Get(); err != ErrConnectionIsClosed || nil != c2 {
+			t.Errorf("[%s] Expected=%#v, Actual=%#v, %#v", tag, ErrConnectionIsClosed, c2, err)
+		}
+		pool.Release(c)
+	}()
+
+	if err := pool.Shutdown(context.Background()); nil != err {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, nil, err)
+		return
+	}
+}
+
+func Test_Pool_Shutdown_4(t *testing.T) {
+	tag := "Shutdown - Unopened pool returns immediately"
+
+	pool, _ := makeStringWrapperPool(1)
+	if err := pool.Shutdown(context.Background()); nil != err {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, nil, err)
+		return
+	}
+}
+
+//
+// Pool: Concurrency, run with go test -race
+//
+
+func Test_Pool_Concurrent_1(t *testing.T) {
+	tag := "Concurrent - Get/Release/Discard/Close destroy every object they create"
+
+	var created, destroyed atomic.Int64
+	pool := &Pool[*stringWrapper]{
+		Size: 4,
+		Factory: func() (*stringWrapper, error) {
+			created.Add(1)
+			return &stringWrapper{Value: "Hello"}, nil
+		},
+		Destroy:             func(*stringWrapper) { destroyed.Add(1) },
+		HealthCheck:         func(*stringWrapper) error { return nil },
+		HealthCheckInterval: time.Millisecond,
+		MaxLifetime:         time.Millisecond * 5,
+	}
+	pool.Open()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				c, err := pool.GetTimeout(time.Millisecond)
+				if nil != err {
+					continue
+				}
+				pool.Len()
+				pool.Stats()
+				if 0 == (i+j)%7 {
+					pool.Discard(c)
+				} else {
+					pool.Release(c)
+				}
+			}
+		}(i)
+	}
+
+	// Close the pool while the objects are in use
+	time.Sleep(time.Millisecond * 5)
+	pool.Close()
+	wg.Wait()
+
+	// Wait for the health checks to stop
+	time.Sleep(time.Millisecond * 10)
+	if created.Load() != destroyed.Load() || pool.InUse() != 0 {
+		t.Errorf("[%s] Expected=%#v, Actual=%#v", tag, created.Load(), destroyed.Load())
+		return
+	}
+}
@@ -1,5 +1,7 @@
 package dog_pool
 
+import "context"
+
 //
 // Typedef for an array of RedisBatchCommand commands
 //
@@ -27,3 +29,21 @@ func (commands RedisBatchCommands) ExecuteBatch(connection RedisClientInterface)
 	// Return the error if any was found
 	return err
 }
+
+//
+// Execute the batch on a connection, within the context's deadline
+//
+// Cancelling the context aborts the batch, and closes the connection.
+//
+func (commands RedisBatchCommands) ExecuteBatchContext(ctx context.Context, connection RedisClientInterface) error {
+	if err := ctx.Err(); nil != err {
+		return err
+	}
+
+	// The commands after the aborted one fail on the closed connection
+	err := commands.ExecuteBatch(withRedisContext(ctx, connection))
+	if ctx_err := pastDeadlineErr(ctx); nil != err && nil != ctx_err {
+		return ctx_err
+	}
+	return err
+}
@@ -1,11 +1,12 @@
 package dog_pool
 
+import "context"
 import "fmt"
 import "time"
 import "github.com/RUNDSP/radix/redis"
-import "github.com/alecthomas/log4go"
 
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
 
 func TestRedisBatchCommandsSpecs(t *testing.T) {
@@ -22,8 +23,8 @@ func TestRedisBatchCommandsSpecs(t *testing.T) {
 func RedisBatchCommandsSpecs(c gospec.Context) {
 
 	c.Specify("[RedisBatchCommands] PING Test", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -41,9 +42,34 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 		c.Expect(commands[0].Reply(), gospec.Satisfies, str == "PONG")
 	})
 
+	c.Specify("[RedisBatchCommands] ExecuteBatchContext aborts the batch when the context is cancelled", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(100)*time.Millisecond)
+		defer cancel()
+
+		// Blocks until the context's deadline
+		blpop := MakeRedisBatchCommand("BLPOP")
+		blpop.WriteStringArgs([]string{"bob", "0"})
+
+		commands := RedisBatchCommands{MakeRedisBatchCommand("PING"), blpop, MakeRedisBatchCommand("PING")}
+		err = commands.ExecuteBatchContext(ctx, server.Connection())
+		c.Expect(err, gospec.Equals, context.DeadlineExceeded)
+		c.Expect(server.Connection().IsClosed(), gospec.Equals, true)
+
+		// Already cancelled
+		err = RedisBatchCommands{MakeRedisBatchCommand("PING")}.ExecuteBatchContext(ctx, server.Connection())
+		c.Expect(err, gospec.Equals, context.DeadlineExceeded)
+	})
+
 	c.Specify("[RedisBatchCommands] Value Exists", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -68,8 +94,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Expire value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -133,8 +159,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Delete values", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -157,8 +183,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Mget values", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -184,8 +210,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Get values", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -216,8 +242,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Set value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -236,8 +262,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] IncrementBy value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -257,8 +283,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] IncrementByFloat value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -278,8 +304,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Hash Value Exists", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -304,8 +330,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Hash Delete values", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -330,8 +356,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Hash Mget values", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -357,8 +383,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Hash Get values", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -389,8 +415,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Hash Set value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -409,8 +435,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchCommands] Hash IncrementBy value", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -430,8 +456,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MakeRedisBatchCommand][Bitop][And] Makes command", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -453,8 +479,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MakeRedisBatchCommand][Bitop][Or] Makes command", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -476,8 +502,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MakeRedisBatchCommand][Bitop][Not] Makes command", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -498,8 +524,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MakeRedisBatchCommand][BitCount] Makes command", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -521,8 +547,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MakeRedisBatchCommand][BitCount] Makes command", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -547,8 +573,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[MakeRedisBatchCommand][SetBit] Makes command", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -583,8 +609,8 @@ func RedisBatchCommandsSpecs(c gospec.Context) {
 // Benchmark Bit Operation A & !B on 10x keys
 //
 func Benchmark_BitOp_ComplementSet_BatchCommands(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
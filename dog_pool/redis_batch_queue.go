@@ -2,10 +2,9 @@ package dog_pool
 
 import "fmt"
 import "time"
-import "github.com/alecthomas/log4go"
 
 type RedisBatchQueue struct {
-	Logger     *log4go.Logger   "Logger for logging updates, errors, etc"
+	Logger     Logger           "(optional) Logger for logging updates, errors, etc, nil logs nothing"
 	Connection *RedisConnection "Connection to Redis"
 
 	QueueSize        uint "How big should the queue of pending commands be?"
@@ -40,8 +39,6 @@ func (p *RedisBatchQueue) String() string {
 // Open the queue
 func (p *RedisBatchQueue) Open() error {
 	switch {
-	case nil == p.Logger:
-		return fmt.Errorf("[RedisBatchQueue][Open] Nil Logger!")
 	case nil != p.queue:
 		return fmt.Errorf("[RedisBatchQueue][Open] Queue is already open!")
 	case nil == p.Connection:
@@ -2,9 +2,9 @@ package dog_pool
 
 import "time"
 import "runtime"
-import "github.com/alecthomas/log4go"
 
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
 
 func TestRedisBatchQueueSpecs(t *testing.T) {
@@ -63,9 +63,8 @@ func RedisBatchQueueSpecs(c gospec.Context) {
 		c.Expect(err, gospec.Satisfies, nil != err)
 		c.Expect(ptr.queue, gospec.Satisfies, nil == ptr.queue)
 		c.Expect(ptr.workers, gospec.Satisfies, 0 == len(ptr.workers))
-		c.Expect(err.Error(), gospec.Equals, "[RedisBatchQueue][Open] Nil Logger!")
+		c.Expect(err.Error(), gospec.Equals, "[RedisBatchQueue][Open] Nil redis connection!")
 
-		ptr.Logger = &log4go.Logger{}
 		ptr.queue = make(chan *RedisBatchCommand, 10)
 
 		err = ptr.Open()
@@ -137,15 +136,15 @@ func RedisBatchQueueSpecs(c gospec.Context) {
 		prev := runtime.GOMAXPROCS(2)
 		defer runtime.GOMAXPROCS(prev)
 
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
 		defer server.Close()
 
 		ptr := &RedisBatchQueue{
-			Logger:           &logger,
+			Logger:           logger,
 			Connection:       server.Connection(),
 			QueueSize:        10,
 			WorkersSize:      5,
@@ -1,18 +1,17 @@
 package dog_pool
 
 import "fmt"
-import "github.com/alecthomas/log4go"
 
 // Worker for running Redis Commands serially in a go routine
 type redisBatchQueueWorker struct {
-	Logger       *log4go.Logger            "Logger for logging updates, errors, etc"
+	Logger       Logger                    "(optional) Logger for logging updates, errors, etc, nil logs nothing"
 	Connection   *RedisConnection          "Connection to Redis"
 	BatchSize    uint                      "Number of RedisBatchCommand's to process at once"
 	CommandQueue <-chan *RedisBatchCommand "Output only queue"
 }
 
 // Make a new instance of redisBatchQueueWorker, or return an error
-func makeRedisBatchQueueWorker(logger *log4go.Logger, connection *RedisConnection, batch_size uint, queue <-chan *RedisBatchCommand) (*redisBatchQueueWorker, error) {
+func makeRedisBatchQueueWorker(logger Logger, connection *RedisConnection, batch_size uint, queue <-chan *RedisBatchCommand) (*redisBatchQueueWorker, error) {
 	p := &redisBatchQueueWorker{
 		Logger:       logger,
 		Connection:   connection,
@@ -21,8 +20,6 @@ func makeRedisBatchQueueWorker(logger *log4go.Logger, connection *RedisConnectio
 	}
 
 	switch {
-	case nil == p.Logger:
-		return nil, fmt.Errorf("[redisBatchQueueWorker][Make] Nil logger!")
 	case nil == p.Connection:
 		return nil, fmt.Errorf("[redisBatchQueueWorker][Make] Nil redis connection!")
 	case nil == p.CommandQueue:
@@ -34,6 +31,13 @@ func makeRedisBatchQueueWorker(logger *log4go.Logger, connection *RedisConnectio
 	}
 }
 
+//
+// Logger to log to, NopLogger when Logger is nil
+//
+func (p *redisBatchQueueWorker) logger() leveledLogger {
+	return logTo(p.Logger)
+}
+
 //
 // Poll the Queue for commands
 //
@@ -146,16 +150,16 @@ func (p *redisBatchQueueWorker) popCommands() (RedisBatchCommands, bool) {
 func (p *redisBatchQueueWorker) runCommands(cmds RedisBatchCommands) {
 	//  Execute the batch and log any high-level errors:
 	if err := cmds.ExecuteBatch(p.Connection); nil != err {
-		p.Logger.Critical("[redisBatchQueueWorker][Run] Error processing Redis Batch: err=%v", err)
+		p.logger().Critical("[redisBatchQueueWorker][Run] Error processing Redis Batch: err=%v", err)
 	}
 
 	// Iterate the commands and log the command + results:
 	for i, cmd := range cmds {
 		switch err := cmd.Reply().Err; {
 		case nil != err:
-			p.Logger.Critical("[redisBatchQueueWorker][Run][%v] Error processing Redis Command: err=%v, cmd=%v", i, err, cmd)
+			p.logger().Critical("[redisBatchQueueWorker][Run][%v] Error processing Redis Command: err=%v, cmd=%v", i, err, cmd)
 		default:
-			p.Logger.Info("[redisBatchQueueWorker][Run][%v] Success processing Redis Command: cmd=%v", i, cmd)
+			p.logger().Info("[redisBatchQueueWorker][Run][%v] Success processing Redis Command: cmd=%v", i, cmd)
 		}
 	}
 }
@@ -3,9 +3,9 @@ package dog_pool
 import "fmt"
 import "time"
 import "runtime"
-import "github.com/alecthomas/log4go"
 
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
 
 func TestRedisBatchQueueWorkerSpecs(t *testing.T) {
@@ -21,12 +21,13 @@ func TestRedisBatchQueueWorkerSpecs(t *testing.T) {
 func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 
 	c.Specify("[RedisBatchQueueWorker][Make]", func() {
+		// A nil logger logs nothing
 		ptr, err := makeRedisBatchQueueWorker(nil, nil, 0, nil)
 		c.Expect(err, gospec.Satisfies, nil != err)
-		c.Expect(err.Error(), gospec.Equals, "[redisBatchQueueWorker][Make] Nil logger!")
+		c.Expect(err.Error(), gospec.Equals, "[redisBatchQueueWorker][Make] Nil redis connection!")
 		c.Expect(ptr, gospec.Satisfies, nil == ptr)
 
-		logger := &log4go.Logger{}
+		logger := NopLogger{}
 		ptr, err = makeRedisBatchQueueWorker(logger, nil, 0, nil)
 		c.Expect(err, gospec.Satisfies, nil != err)
 		c.Expect(err.Error(), gospec.Equals, "[redisBatchQueueWorker][Make] Nil redis connection!")
@@ -53,8 +54,8 @@ func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisBatchQueueWorker][runCommands]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -63,7 +64,7 @@ func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 		queue := make(chan *RedisBatchCommand)
 		defer close(queue)
 
-		worker, worker_err := makeRedisBatchQueueWorker(&logger, server.Connection(), 1, queue)
+		worker, worker_err := makeRedisBatchQueueWorker(logger, server.Connection(), 1, queue)
 		c.Expect(worker_err, gospec.Equals, nil)
 		c.Expect(worker, gospec.Satisfies, nil != worker)
 
@@ -84,7 +85,7 @@ func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 		prev := runtime.GOMAXPROCS(2)
 		defer runtime.GOMAXPROCS(prev)
 
-		logger := &log4go.Logger{}
+		logger := NopLogger{}
 		connection := &RedisConnection{}
 		batch_size := uint(1)
 		queue := make(chan *RedisBatchCommand, 2)
@@ -130,7 +131,7 @@ func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 		prev := runtime.GOMAXPROCS(2)
 		defer runtime.GOMAXPROCS(prev)
 
-		logger := &log4go.Logger{}
+		logger := NopLogger{}
 		connection := &RedisConnection{}
 		batch_size := uint(1)
 		queue := make(chan *RedisBatchCommand, 2)
@@ -158,7 +159,7 @@ func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 		prev := runtime.GOMAXPROCS(2)
 		defer runtime.GOMAXPROCS(prev)
 
-		logger := &log4go.Logger{}
+		logger := NopLogger{}
 		connection := &RedisConnection{}
 		batch_size := uint(10)
 		queue := make(chan *RedisBatchCommand, int(batch_size*3))
@@ -226,8 +227,8 @@ func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 		prev := runtime.GOMAXPROCS(2)
 		defer runtime.GOMAXPROCS(prev)
 
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -237,7 +238,7 @@ func RedisBatchQueueWorkerSpecs(c gospec.Context) {
 		queue := make(chan *RedisBatchCommand, int(batch_size*3))
 		// defer close(queue)
 
-		ptr, err := makeRedisBatchQueueWorker(&logger, server.Connection(), batch_size, queue)
+		ptr, err := makeRedisBatchQueueWorker(logger, server.Connection(), batch_size, queue)
 		c.Expect(err, gospec.Satisfies, nil == err)
 		c.Expect(ptr, gospec.Satisfies, nil != ptr)
 
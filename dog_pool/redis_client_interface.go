@@ -6,6 +6,7 @@
 
 package dog_pool
 
+import "context"
 import "github.com/RUNDSP/radix/redis"
 
 type RedisClientInterface interface {
@@ -24,3 +25,20 @@ type RedisClientInterface interface {
 	// if the pipeline queue is empty.
 	GetReply() *redis.Reply
 }
+
+//
+// Redis Client Interface with context-aware replies
+//
+// Interface implemented by dog_pool.RedisConnection
+//
+type RedisContextClientInterface interface {
+	RedisClientInterface
+
+	// CmdContext calls the given Redis command, within the context's deadline.
+	CmdContext(ctx context.Context, cmd string, args ...interface{}) *redis.Reply
+
+	// GetReplyContext returns the reply for the next request in the pipeline queue,
+	// within the context's deadline.
+	// Cancelling the context aborts the read, and closes the connection.
+	GetReplyContext(ctx context.Context) *redis.Reply
+}
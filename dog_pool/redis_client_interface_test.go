@@ -30,6 +30,14 @@ func RedisClientInterfaceSpecs(c gospec.Context) {
 		c.Expect(redis_interface, gospec.Satisfies, true)
 	})
 
+	c.Specify("[RedisClientInterface] RedisConnection satisfies RedisContextClientInterface", func() {
+		connection := &RedisConnection{}
+
+		// Wont' compile unless it implements the interface
+		var redis_interface RedisContextClientInterface = connection
+		c.Expect(redis_interface, gospec.Satisfies, true)
+	})
+
 	c.Specify("[RedisClientInterface] redis.Client satisfies RedisClientInterface", func() {
 		client := &redis.Client{}
 
@@ -37,4 +45,28 @@ func RedisClientInterfaceSpecs(c gospec.Context) {
 		var redis_interface RedisClientInterface = client
 		c.Expect(redis_interface, gospec.Satisfies, true)
 	})
+
+	c.Specify("[RedisClientInterface] ShardedRedisClient satisfies RedisClientInterface", func() {
+		client := &ShardedRedisClient{}
+
+		// Wont' compile unless it implements the interface
+		var redis_interface RedisClientInterface = client
+		c.Expect(redis_interface, gospec.Satisfies, true)
+	})
+
+	c.Specify("[RedisClientInterface] RedisClusterClient satisfies RedisClientInterface", func() {
+		client := &RedisClusterClient{}
+
+		// Wont' compile unless it implements the interface
+		var redis_interface RedisClientInterface = client
+		c.Expect(redis_interface, gospec.Satisfies, true)
+	})
+
+	c.Specify("[RedisClientInterface] ReplicatedRedisClient satisfies RedisClientInterface", func() {
+		client := &ReplicatedRedisClient{}
+
+		// Wont' compile unless it implements the interface
+		var redis_interface RedisClientInterface = client
+		c.Expect(redis_interface, gospec.Satisfies, true)
+	})
 }
@@ -0,0 +1,450 @@
+//
+// Redis Cluster topology written in GO
+//
+
+package dog_pool
+
+import "crypto/tls"
+import "fmt"
+import "net"
+import "sort"
+import "strconv"
+import "strings"
+import "sync"
+import "time"
+import "github.com/RUNDSP/radix/redis"
+
+//
+// Number of hash slots in a Redis Cluster
+//
+const cluster_slots = 16384
+
+//
+// Number of MOVED/ASK redirects to follow before giving up on a command
+//
+const cluster_max_redirects = 5
+
+//
+// Redis Cluster wrapper, maps the hash slots to a connection pool for each master
+//
+// The slot map is loaded with CLUSTER SLOTS from the Urls when the cluster is opened,
+// and refreshed when a node redirects a command with MOVED.
+// A RedisCluster is safe for concurrent use, pipeline commands with a RedisClusterClient.
+//
+type RedisCluster struct {
+	Mode    ConnectionMode "How should we prepare each node's connection pool?"
+	Size    int            "(Max) Pool size for each node"
+	Urls    []string       "Redis Cluster nodes to load the slot map from"
+	Logger  Logger         "(optional) Logger we are using in the cluster and the nodes' connection pools, nil logs nothing"
+	Timeout time.Duration  "Timeout to use for connecting to Redis"
+
+	MinRefreshInterval time.Duration "(optional) Refresh the slot map at most this often after MOVED redirects, defaults to 1s"
+
+	TLSConfig *tls.Config "(optional) TLS configuration for the nodes, nil dials them without TLS"
+
+	mutex       sync.RWMutex                    "Guards slots, nodes and refreshedAt"
+	refreshing  sync.Mutex                      "Serializes Open, Close and Refresh"
+	slots       []string                        "Address of the master serving each slot, nil if the cluster is not open"
+	nodes       map[string]*RedisConnectionPool "Connection pool for each node"
+	refreshedAt time.Time                       "When the slot map was last loaded"
+}
+
+func (p *RedisCluster) String() string {
+	return fmt.Sprintf("RedisCluster { Size=%v, Urls=%v, Timeout=%v }", p.Size, redactRedisUrls(p.Urls), p.Timeout)
+}
+
+//
+// Logger to log to, NopLogger when Logger is nil
+//
+func (p *RedisCluster) logger() leveledLogger {
+	return logTo(p.Logger)
+}
+
+//
+// Is the cluster open?
+//
+func (p *RedisCluster) IsOpen() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return nil != p.slots
+}
+
+//
+// Is the cluster closed?
+//
+func (p *RedisCluster) IsClosed() bool {
+	return !p.IsOpen()
+}
+
+//
+// Open the cluster, loading the slot map from the first of the Urls that answers
+//
+func (p *RedisCluster) Open() error {
+	// Default to the 15s timeout
+	if time.Duration(0) == p.Timeout {
+		p.Timeout = default_timeout
+	}
+
+	// Default to refreshing at most once a second
+	if time.Duration(0) == p.MinRefreshInterval {
+		p.MinRefreshInterval = time.Duration(1) * time.Second
+	}
+
+	return p.Refresh()
+}
+
+//
+// Close the nodes' connection pools
+//
+func (p *RedisCluster) Close() {
+	p.refreshing.Lock()
+	defer p.refreshing.Unlock()
+
+	p.mutex.Lock()
+	nodes := p.nodes
+	p.slots, p.nodes = nil, nil
+	p.mutex.Unlock()
+
+	for _, pool := range nodes {
+		pool.Close()
+	}
+}
+
+//
+// Reload the slot map from the known nodes, or the Urls
+//
+// Pools are opened for the new masters, and closed for the nodes that no longer serve any slots.
+//
+func (p *RedisCluster) Refresh() error {
+	p.refreshing.Lock()
+	defer p.refreshing.Unlock()
+
+	urls := p.seeds()
+	var err error
+	for _, url := range urls {
+		var slots []string
+		if slots, err = p.loadSlots(url); nil != err {
+			p.logger().Warn("[RedisCluster][Refresh][%s] Error = %v", redactRedisUrl(url), err)
+			continue
+		}
+
+		p.setSlots(slots)
+		return nil
+	}
+	return fmt.Errorf("[RedisCluster][Refresh] Failed to load the slot map from Urls=%v, Error = %v", redactRedisUrls(urls), err)
+}
+
+//
+// Address of the master serving the key's slot
+//
+func (p *RedisCluster) NodeOf(key string) (string, error) {
+	return p.nodeOfSlot(ClusterSlot(key))
+}
+
+//
+// Hash slot of the key, hashing only the {hashtag} if the key has one
+//
+func ClusterSlot(key string) int {
+	return int(crc16([]byte(hashTag(key))) % cluster_slots)
+}
+
+//
+//  ========================================
+//
+// RedisCluster Utils:
+//
+//  ========================================
+//
+
+//
+// Known nodes first, then the Urls
+//
+func (p *RedisCluster) seeds() []string {
+	p.mutex.RLock()
+	known := make([]string, 0, len(p.nodes))
+	for addr := range p.nodes {
+		known = append(known, addr)
+	}
+	p.mutex.RUnlock()
+	sort.Strings(known)
+
+	output := []string{}
+	seen := map[string]bool{}
+	for _, url := range append(known, p.Urls...) {
+		if !seen[url] {
+			seen[url] = true
+			output = append(output, url)
+		}
+	}
+	return output
+}
+
+//
+// Ask the node for the slot map
+//
+func (p *RedisCluster) loadSlots(url string) ([]string, error) {
+	c := &RedisConnection{Url: url, Id: "Cluster", Logger: p.Logger, Timeout: p.Timeout, TLSConfig: p.TLSConfig}
+	defer c.Close()
+
+	reply := c.Cmd("CLUSTER", "SLOTS")
+	if nil != reply.Err {
+		return nil, reply.Err
+	}
+	return parseClusterSlots(url, reply)
+}
+
+//
+// Parse the CLUSTER SLOTS reply: [[start, end, [host, port, id], replicas...], ...]
+// Nodes that don't know their own host reply with "", use the host of the url that was asked
+//
+func parseClusterSlots(url string, reply *redis.Reply) ([]string, error) {
+	if redis.MultiReply != reply.Type {
+		return nil, fmt.Errorf("[RedisCluster][Refresh][%s] Expected a MultiReply from CLUSTER SLOTS, Actual=%v", redactRedisUrl(url), reply)
+	}
+
+	slots := make([]string, cluster_slots)
+	for _, elem := range reply.Elems {
+		if len(elem.Elems) < 3 || len(elem.Elems[2].Elems) < 2 {
+			return nil, fmt.Errorf("[RedisCluster][Refresh][%s] Invalid slot range from CLUSTER SLOTS, reply=%v", redactRedisUrl(url), elem)
+		}
+
+		start, start_err := elem.Elems[0].Int()
+		end, end_err := elem.Elems[1].Int()
+		host, host_err := elem.Elems[2].Elems[0].Str()
+		port, port_err := elem.Elems[2].Elems[1].Int()
+		for _, err := range []error{start_err, end_err, host_err, port_err} {
+			if nil != err {
+				return nil, err
+			}
+		}
+
+		if start < 0 || end >= cluster_slots || start > end {
+			return nil, fmt.Errorf("[RedisCluster][Refresh][%s] Invalid slot range[%v-%v] from CLUSTER SLOTS", redactRedisUrl(url), start, end)
+		}
+		if "" == host {
+			host, _, _ = net.SplitHostPort(url)
+		}
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		for slot := start; slot <= end; slot++ {
+			slots[slot] = addr
+		}
+	}
+	return slots, nil
+}
+
+//
+// Swap in the slot map, opening the new masters' pools and closing the unused ones
+//
+func (p *RedisCluster) setSlots(slots []string) {
+	masters := map[string]bool{}
+	for _, addr := range slots {
+		if "" != addr {
+			masters[addr] = true
+		}
+	}
+
+	// Open the new masters' pools outside of the lock
+	p.mutex.RLock()
+	opened := map[string]*RedisConnectionPool{}
+	for addr := range masters {
+		if _, ok := p.nodes[addr]; !ok {
+			opened[addr] = nil
+		}
+	}
+	p.mutex.RUnlock()
+	for addr := range opened {
+		pool := p.newNodePool(addr)
+		if err := pool.Open(); nil != err {
+			p.logger().Warn("[RedisCluster][Refresh][%s] Failed to open the connection pool, Error = %v", addr, err)
+			delete(opened, addr)
+			continue
+		}
+		opened[addr] = pool
+	}
+
+	p.mutex.Lock()
+	if nil == p.nodes {
+		p.nodes = make(map[string]*RedisConnectionPool)
+	}
+	for addr, pool := range opened {
+		p.nodes[addr] = pool
+	}
+	unused := []*RedisConnectionPool{}
+	for addr, pool := range p.nodes {
+		if !masters[addr] {
+			unused = append(unused, pool)
+			delete(p.nodes, addr)
+		}
+	}
+	p.slots = slots
+	p.refreshedAt = time.Now()
+	p.mutex.Unlock()
+
+	// Borrowed connections are closed when they are Push'd back
+	for _, pool := range unused {
+		pool.Close()
+	}
+}
+
+//
+// Address of the master serving the slot
+//
+func (p *RedisCluster) nodeOfSlot(slot int) (string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	switch {
+	case nil == p.slots:
+		return "", ErrConnectionIsClosed
+	case "" == p.slots[slot]:
+		return "", ErrClusterSlotNotServed
+	}
+	return p.slots[slot], nil
+}
+
+//
+// Connection pool for the node, opening one if the node is new
+//
+func (p *RedisCluster) nodePool(addr string) (*RedisConnectionPool, error) {
+	p.mutex.RLock()
+	pool, ok := p.nodes[addr]
+	closed := nil == p.slots
+	p.mutex.RUnlock()
+
+	switch {
+	case closed:
+		return nil, ErrConnectionIsClosed
+	case ok:
+		return pool, nil
+	}
+
+	pool = p.newNodePool(addr)
+	if err := pool.Open(); nil != err {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Closed or opened by someone else while we were opening the pool?
+	if existing, ok := p.nodes[addr]; ok || nil == p.slots {
+		pool.Close()
+		if !ok {
+			return nil, ErrConnectionIsClosed
+		}
+		return existing, nil
+	}
+	p.nodes[addr] = pool
+	return pool, nil
+}
+
+func (p *RedisCluster) newNodePool(addr string) *RedisConnectionPool {
+	return &RedisConnectionPool{Mode: p.Mode, Size: p.Size, Urls: []string{addr}, Logger: p.Logger, Timeout: p.Timeout, TLSConfig: p.TLSConfig}
+}
+
+//
+// Return a borrowed connection to its node's pool, or close it if the node is gone
+//
+func (p *RedisCluster) push(addr string, c *RedisConnection) {
+	p.mutex.RLock()
+	pool, ok := p.nodes[addr]
+	p.mutex.RUnlock()
+
+	if !ok {
+		c.Close()
+		return
+	}
+	pool.Push(c)
+}
+
+//
+// The slot moved to addr, remember it and refresh the slot map if it is old
+//
+func (p *RedisCluster) moved(slot int, addr string) {
+	p.mutex.Lock()
+	if nil != p.slots {
+		p.slots[slot] = addr
+	}
+	stale := time.Since(p.refreshedAt) >= p.MinRefreshInterval
+	p.mutex.Unlock()
+
+	if stale {
+		if err := p.Refresh(); nil != err {
+			p.logger().Warn("[RedisCluster][Moved][%v/%s] Error = %v", slot, addr, err)
+		}
+	}
+}
+
+//
+// Send the command to the node the slot was redirected to, on a connection of its own
+//
+func (p *RedisCluster) redirect(addr string, asking bool, cmd string, args []interface{}) *redis.Reply {
+	pool, err := p.nodePool(addr)
+	if nil != err {
+		return &redis.Reply{Type: redis.ErrorReply, Err: err}
+	}
+
+	var reply *redis.Reply
+	err = pool.Do(func(c RedisDsl) error {
+		// ASK redirects are for one command, and must be preceded by ASKING
+		if asking {
+			if reply = c.Cmd("ASKING"); redis.ErrorReply == reply.Type {
+				return reply.Err
+			}
+		}
+		reply = c.Cmd(cmd, args...)
+		return nil
+	})
+	if nil != err && nil == reply {
+		return &redis.Reply{Type: redis.ErrorReply, Err: err}
+	}
+	return reply
+}
+
+//
+// Parse a "MOVED 3999 127.0.0.1:6381" or "ASK 3999 127.0.0.1:6381" error
+//
+func parseClusterRedirect(err error) (kind string, slot int, addr string, ok bool) {
+	if nil == err {
+		return "", 0, "", false
+	}
+
+	fields := strings.Fields(err.Error())
+	if 3 != len(fields) || ("MOVED" != fields[0] && "ASK" != fields[0]) {
+		return "", 0, "", false
+	}
+
+	slot, slot_err := strconv.Atoi(fields[1])
+	if nil != slot_err || slot < 0 || slot >= cluster_slots {
+		return "", 0, "", false
+	}
+	return fields[0], slot, fields[2], true
+}
+
+//
+// Is the error a MOVED/ASK redirect?
+//
+func isClusterRedirect(err error) bool {
+	_, _, _, ok := parseClusterRedirect(err)
+	return ok
+}
+
+//
+// CRC16-CCITT (XMODEM), the checksum Redis Cluster hashes the keys with
+//
+func crc16(data []byte) uint16 {
+	crc := uint16(0)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if 0 != crc&0x8000 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
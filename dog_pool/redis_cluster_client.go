@@ -0,0 +1,173 @@
+//
+// Redis Cluster Client written in GO
+//
+
+package dog_pool
+
+import "fmt"
+import "github.com/RUNDSP/radix/redis"
+
+//
+// Redis Client that routes the commands to the master serving their key's slot
+//
+// The key is the first argument, except for BITOP (the destination key) and EVAL/EVALSHA (the first key).
+// Commands without a key go to the master serving slot 0.
+// Multi-key commands must share a {hashtag}, Redis Cluster rejects them with CROSSSLOT otherwise.
+//
+// MOVED and ASK redirects are followed on a connection of their own,
+// a MOVED redirect also updates the cluster's slot map.
+//
+// Connections are Pop'd from the nodes' pools while commands are pipelined,
+// and Push'd back when the pipeline is empty, so RedisBatchCommands.ExecuteBatch
+// pipelines a batch on each node.
+// Like RedisConnection, a RedisClusterClient is not safe for concurrent use.
+//
+type RedisClusterClient struct {
+	Cluster *RedisCluster "Open cluster to route the commands to"
+
+	connections map[string]*RedisConnection "Connections borrowed for the pipeline, by node"
+	queue       []*clusterCommand           "Pipelined commands waiting for their replies"
+}
+
+//
+// Pipelined command, and the node it was sent to
+//
+type clusterCommand struct {
+	cmd  string
+	args []interface{}
+	node string
+	err  error "Error routing the command, or borrowing a connection for its node"
+}
+
+//
+// Make a client for the cluster
+//
+func MakeRedisClusterClient(cluster *RedisCluster) *RedisClusterClient {
+	return &RedisClusterClient{Cluster: cluster, connections: make(map[string]*RedisConnection)}
+}
+
+func (p *RedisClusterClient) String() string {
+	return fmt.Sprintf("RedisClusterClient { Cluster=%v, Pipelined=%v }", p.Cluster, len(p.queue))
+}
+
+//
+//  ========================================
+//
+// RedisClientInterface implementation:
+//
+//  ========================================
+//
+
+//
+// Close discards the pipelined commands, and Push'es the borrowed connections back to their pools.
+// Connections with unread replies are closed first.
+//
+func (p *RedisClusterClient) Close() error {
+	if 0 != len(p.queue) {
+		for _, c := range p.connections {
+			c.Close()
+		}
+	}
+
+	p.queue = nil
+	p.release()
+	return nil
+}
+
+//
+// Cmd calls the given Redis command on the master serving the key's slot:
+// - Calls Append(...)
+// - Returns GetReply()
+//
+func (p *RedisClusterClient) Cmd(cmd string, args ...interface{}) *redis.Reply {
+	p.Append(cmd, args...)
+	return p.GetReply()
+}
+
+//
+// Append adds the given call to the pipeline queue of the master serving the key's slot.
+// Use GetReply() to read the reply.
+//
+func (p *RedisClusterClient) Append(cmd string, args ...interface{}) {
+	args = flattenArgs(args)
+	command := &clusterCommand{cmd: cmd, args: args}
+	p.queue = append(p.queue, command)
+
+	command.node, command.err = p.Cluster.nodeOfSlot(ClusterSlot(commandKey(cmd, args)))
+	if nil != command.err {
+		return
+	}
+
+	c, ok := p.connections[command.node]
+	if !ok {
+		var pool *RedisConnectionPool
+		if pool, command.err = p.Cluster.nodePool(command.node); nil != command.err {
+			return
+		}
+		if c, command.err = pool.Pop(); nil != command.err {
+			return
+		}
+		p.connections[command.node] = c
+	}
+
+	c.Append(cmd, args...)
+}
+
+//
+// GetReply returns the reply for the next request in the pipeline queue,
+// following the MOVED and ASK redirects.
+// Error reply with PipelineQueueEmptyError is returned,
+// if the pipeline queue is empty.
+//
+func (p *RedisClusterClient) GetReply() *redis.Reply {
+	if 0 == len(p.queue) {
+		return &redis.Reply{Type: redis.ErrorReply, Err: redis.PipelineQueueEmptyError}
+	}
+
+	command := p.queue[0]
+	p.queue = p.queue[1:]
+
+	var reply *redis.Reply
+	if nil != command.err {
+		reply = &redis.Reply{Type: redis.ErrorReply, Err: command.err}
+	} else {
+		reply = p.connections[command.node].GetReply()
+	}
+
+	// Follow the redirects, MOVED means the slot map is out of date
+	for i := 0; i < cluster_max_redirects && redis.ErrorReply == reply.Type; i++ {
+		kind, slot, addr, ok := parseClusterRedirect(reply.Err)
+		if !ok {
+			break
+		}
+		if "MOVED" == kind {
+			p.Cluster.moved(slot, addr)
+		}
+		reply = p.Cluster.redirect(addr, "ASK" == kind, command.cmd, command.args)
+	}
+
+	// Return the connections once the pipeline is empty
+	if 0 == len(p.queue) {
+		p.release()
+	}
+
+	return reply
+}
+
+//
+//  ========================================
+//
+// RedisClusterClient Utils:
+//
+//  ========================================
+//
+
+//
+// Push the borrowed connections back to their pools
+//
+func (p *RedisClusterClient) release() {
+	for node, c := range p.connections {
+		p.Cluster.push(node, c)
+		delete(p.connections, node)
+	}
+}
@@ -0,0 +1,50 @@
+package dog_pool
+
+import "testing"
+import "log"
+import "github.com/RUNDSP/radix/redis"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestRedisClusterClientSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(RedisClusterClientSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func RedisClusterClientSpecs(c gospec.Context) {
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+
+	c.Specify("[RedisClusterClient] Routes by the command's key", func() {
+		c.Expect(commandKey("GET", []interface{}{"bob"}), gospec.Equals, "bob")
+		c.Expect(commandKey("SET", []interface{}{[]byte("bob"), "value"}), gospec.Equals, "bob")
+		c.Expect(commandKey("PING", []interface{}{}), gospec.Equals, "")
+		c.Expect(commandKey("BITOP", []interface{}{"AND", "dest", "a", "b"}), gospec.Equals, "dest")
+		c.Expect(commandKey("EVAL", []interface{}{"return 1", "1", "bob"}), gospec.Equals, "bob")
+		c.Expect(commandKey("EVALSHA", []interface{}{"abc", 0}), gospec.Equals, "")
+	})
+
+	c.Specify("[RedisClusterClient] GetReply on an empty pipeline returns an error", func() {
+		client := MakeRedisClusterClient(&RedisCluster{Logger: logger})
+		reply := client.GetReply()
+		c.Expect(reply.Type, gospec.Equals, redis.ErrorReply)
+		c.Expect(reply.Err, gospec.Equals, redis.PipelineQueueEmptyError)
+	})
+
+	c.Specify("[RedisClusterClient] Commands on a closed cluster fail", func() {
+		client := MakeRedisClusterClient(&RedisCluster{Logger: logger})
+		defer client.Close()
+
+		reply := client.Cmd("GET", "bob")
+		c.Expect(reply.Type, gospec.Equals, redis.ErrorReply)
+		c.Expect(reply.Err, gospec.Equals, ErrConnectionIsClosed)
+
+		// Batches fail on every command
+		commands := RedisBatchCommands{MakeRedisBatchCommandGet("a"), MakeRedisBatchCommandGet("b")}
+		c.Expect(commands.ExecuteBatch(client), gospec.Equals, ErrConnectionIsClosed)
+		c.Expect(len(client.queue), gospec.Equals, 0)
+	})
+}
@@ -0,0 +1,132 @@
+package dog_pool
+
+import "fmt"
+import "errors"
+import "os"
+import "strings"
+import "time"
+
+//
+// Redis Cluster of local redis-server processes, each one is a master for an equal share of the slots
+//
+// Requires redis-server 7+, for --cluster-port and CLUSTER ADDSLOTSRANGE.
+//
+type RedisClusterProcess struct {
+	servers []*RedisServerProcess
+	dir     string "Directory for the nodes' cluster config files"
+}
+
+func StartRedisCluster(logger Logger, masters int) (*RedisClusterProcess, error) {
+	if masters < 1 {
+		return nil, errors.New("Cluster needs at least one master")
+	}
+
+	dir, err := os.MkdirTemp("", "dog_pool_cluster")
+	if nil != err {
+		return nil, err
+	}
+	cluster := &RedisClusterProcess{dir: dir}
+
+	// Start the servers, with the cluster bus on a port of its own
+	bus_ports := make([]int, masters)
+	for i := range bus_ports {
+		if bus_ports[i], err = findPort(); nil != err {
+			cluster.Close()
+			return nil, err
+		}
+
+		server, err := startRedisServer(logger, func(port int) ([]string, error) {
+			return []string{
+				"--port", fmt.Sprintf("%d", port),
+				"--cluster-enabled", "yes",
+				"--cluster-port", fmt.Sprintf("%d", bus_ports[i]),
+				"--cluster-config-file", fmt.Sprintf("nodes-%d.conf", port),
+				"--dir", dir,
+				"--save", "",
+				"--appendonly", "no",
+			}, nil
+		})
+		if nil != err {
+			cluster.Close()
+			return nil, err
+		}
+		cluster.servers = append(cluster.servers, server)
+	}
+
+	// Split the slots between the servers, and introduce them to the first one
+	for i, server := range cluster.servers {
+		connection := server.Connection()
+		start := i * cluster_slots / masters
+		end := (i+1)*cluster_slots/masters - 1
+		if reply := connection.Cmd("CLUSTER", "ADDSLOTSRANGE", start, end); nil != reply.Err {
+			cluster.Close()
+			return nil, reply.Err
+		}
+		if i > 0 {
+			if reply := connection.Cmd("CLUSTER", "MEET", "127.0.0.1", cluster.servers[0].port, bus_ports[0]); nil != reply.Err {
+				cluster.Close()
+				return nil, reply.Err
+			}
+		}
+	}
+
+	// Wait for the nodes to agree on the slots
+	for _, server := range cluster.servers {
+		if err := server.waitForClusterOk(time.Duration(10) * time.Second); nil != err {
+			cluster.Close()
+			return nil, err
+		}
+	}
+
+	return cluster, nil
+}
+
+//
+// Urls of the cluster's masters
+//
+func (p *RedisClusterProcess) Urls() []string {
+	output := make([]string, len(p.servers))
+	for i, server := range p.servers {
+		output[i] = server.Url()
+	}
+	return output
+}
+
+//
+// Servers running the cluster's masters
+//
+func (p *RedisClusterProcess) Servers() []*RedisServerProcess {
+	return p.servers
+}
+
+//
+// Close the redis-servers and remove their cluster config files
+//
+func (p *RedisClusterProcess) Close() error {
+	for _, server := range p.servers {
+		server.Close()
+	}
+	p.servers = nil
+
+	if "" != p.dir {
+		os.RemoveAll(p.dir)
+	}
+	p.dir = ""
+
+	return nil
+}
+
+//
+// Poll CLUSTER INFO until the node reports cluster_state:ok
+//
+func (p *RedisServerProcess) waitForClusterOk(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		info, _ := p.Connection().Cmd("CLUSTER", "INFO").Str()
+		if strings.Contains(info, "cluster_state:ok") {
+			return nil
+		}
+		time.Sleep(time.Duration(100) * time.Millisecond)
+	}
+	return fmt.Errorf("Cluster node[%s] is not ok after %v", p.Url(), timeout)
+}
@@ -0,0 +1,256 @@
+package dog_pool
+
+import "errors"
+import "fmt"
+import "testing"
+import "log"
+import "github.com/RUNDSP/radix/redis"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestRedisClusterSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(RedisClusterSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func RedisClusterSpecs(c gospec.Context) {
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+
+	// Node ids of the servers, and the server that isn't serving the slot
+	nodeId := func(server *RedisServerProcess) string {
+		id, err := server.Connection().Cmd("CLUSTER", "MYID").Str()
+		if nil != err {
+			panic(err)
+		}
+		return id
+	}
+	otherServer := func(process *RedisClusterProcess, url string) (*RedisServerProcess, *RedisServerProcess) {
+		servers := process.Servers()
+		if servers[0].Url() == url {
+			return servers[0], servers[1]
+		}
+		return servers[1], servers[0]
+	}
+
+	c.Specify("[RedisCluster] CRC16 matches the Redis Cluster spec", func() {
+		c.Expect(crc16([]byte("123456789")), gospec.Equals, uint16(0x31c3))
+	})
+
+	c.Specify("[RedisCluster] Hashes the keys to slots", func() {
+		c.Expect(ClusterSlot(""), gospec.Equals, 0)
+		c.Expect(ClusterSlot("foo"), gospec.Equals, 12182)
+		c.Expect(ClusterSlot("bar"), gospec.Equals, 5061)
+		c.Expect(ClusterSlot("{user1000}.following"), gospec.Equals, ClusterSlot("{user1000}.followers"))
+		c.Expect(ClusterSlot("{}.following"), gospec.Not(gospec.Equals), ClusterSlot("{}.followers"))
+	})
+
+	c.Specify("[RedisCluster] Parses the MOVED and ASK redirects", func() {
+		kind, slot, addr, ok := parseClusterRedirect(errors.New("MOVED 3999 127.0.0.1:6381"))
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(kind, gospec.Equals, "MOVED")
+		c.Expect(slot, gospec.Equals, 3999)
+		c.Expect(addr, gospec.Equals, "127.0.0.1:6381")
+
+		kind, slot, addr, ok = parseClusterRedirect(errors.New("ASK 12182 127.0.0.1:6382"))
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(kind, gospec.Equals, "ASK")
+		c.Expect(slot, gospec.Equals, 12182)
+		c.Expect(addr, gospec.Equals, "127.0.0.1:6382")
+
+		c.Expect(isClusterRedirect(nil), gospec.Equals, false)
+		c.Expect(isClusterRedirect(errors.New("ERR unknown command")), gospec.Equals, false)
+		c.Expect(isClusterRedirect(errors.New("MOVED 16384 127.0.0.1:6381")), gospec.Equals, false)
+		c.Expect(isClusterRedirect(errors.New("MOVED abc 127.0.0.1:6381")), gospec.Equals, false)
+	})
+
+	c.Specify("[RedisCluster] Invalid CLUSTER SLOTS replies are errors", func() {
+		_, err := parseClusterSlots("127.0.0.1:6991", &redis.Reply{Type: redis.NilReply})
+		c.Expect(err, gospec.Satisfies, nil != err)
+
+		_, err = parseClusterSlots("127.0.0.1:6991", &redis.Reply{Type: redis.MultiReply, Elems: []*redis.Reply{&redis.Reply{Type: redis.MultiReply}}})
+		c.Expect(err, gospec.Satisfies, nil != err)
+	})
+
+	c.Specify("[RedisCluster] Fails to Open when no Url answers", func() {
+		cluster := &RedisCluster{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991", "127.0.0.1:6992"}, Logger: logger}
+		defer cluster.Close()
+
+		err := cluster.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(cluster.IsClosed(), gospec.Equals, true)
+
+		_, err = cluster.NodeOf("bob")
+		c.Expect(err, gospec.Equals, ErrConnectionIsClosed)
+	})
+
+	c.Specify("[RedisCluster] Loads the slot map from the cluster", func() {
+		process, err := StartRedisCluster(logger, 2)
+		if nil != err {
+			panic(err)
+		}
+		defer process.Close()
+
+		cluster := &RedisCluster{Mode: LAZY, Size: 2, Urls: process.Urls()[0:1], Logger: logger}
+		defer cluster.Close()
+		c.Expect(cluster.Open(), gospec.Equals, nil)
+		c.Expect(cluster.IsOpen(), gospec.Equals, true)
+
+		// The first half of the slots is on the first server
+		node, err := cluster.nodeOfSlot(0)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(node, gospec.Equals, process.Urls()[0])
+
+		node, err = cluster.nodeOfSlot(cluster_slots - 1)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(node, gospec.Equals, process.Urls()[1])
+	})
+
+	c.Specify("[RedisCluster] MOVED redirects don't close the connection", func() {
+		process, err := StartRedisCluster(logger, 2)
+		if nil != err {
+			panic(err)
+		}
+		defer process.Close()
+
+		// Slot 0 is on the first server
+		c.Expect(ClusterSlot("{06S}"), gospec.Equals, 0)
+		connection := process.Servers()[1].Connection()
+		reply := connection.Cmd("GET", "{06S}")
+		c.Expect(isClusterRedirect(reply.Err), gospec.Equals, true)
+		c.Expect(connection.IsOpen(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisClusterClient] Routes the commands and batches to the masters", func() {
+		process, err := StartRedisCluster(logger, 2)
+		if nil != err {
+			panic(err)
+		}
+		defer process.Close()
+
+		cluster := &RedisCluster{Mode: LAZY, Size: 2, Urls: process.Urls(), Logger: logger}
+		defer cluster.Close()
+		c.Expect(cluster.Open(), gospec.Equals, nil)
+
+		client := MakeRedisClusterClient(cluster)
+		defer client.Close()
+
+		commands := RedisBatchCommands{}
+		for i := 0; i < 10; i++ {
+			commands = append(commands, MakeRedisBatchCommandSet(fmt.Sprintf("key:%d", i), []byte(fmt.Sprintf("value:%d", i))))
+		}
+		c.Expect(commands.ExecuteBatch(client), gospec.Equals, nil)
+
+		for i := 0; i < 10; i++ {
+			value, err := client.Cmd("GET", fmt.Sprintf("key:%d", i)).Str()
+			c.Expect(err, gospec.Equals, nil)
+			c.Expect(value, gospec.Equals, fmt.Sprintf("value:%d", i))
+		}
+
+		// Keys sharing a hashtag are on the same master
+		c.Expect(client.Cmd("MSET", "{user:1}:name", "bob", "{user:1}:email", "bob@example.com").Err, gospec.Equals, nil)
+		values, err := client.Cmd("MGET", "{user:1}:name", "{user:1}:email").List()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(values, gospec.Equals, []string{"bob", "bob@example.com"})
+	})
+
+	c.Specify("[RedisClusterClient] Close doesn't leave unread replies on the connections", func() {
+		process, err := StartRedisCluster(logger, 1)
+		if nil != err {
+			panic(err)
+		}
+		defer process.Close()
+
+		cluster := &RedisCluster{Mode: LAZY, Size: 1, Urls: process.Urls(), Logger: logger}
+		defer cluster.Close()
+		c.Expect(cluster.Open(), gospec.Equals, nil)
+
+		client := MakeRedisClusterClient(cluster)
+		c.Expect(client.Cmd("SET", "{user:1}:bob", "stale").Err, gospec.Equals, nil)
+		c.Expect(client.Cmd("SET", "{user:1}:alice", "fresh").Err, gospec.Equals, nil)
+
+		// Close with the GET's reply unread
+		client.Append("GET", "{user:1}:bob")
+		c.Expect(client.Close(), gospec.Equals, nil)
+
+		node, err := cluster.NodeOf("{user:1}:alice")
+		c.Expect(err, gospec.Equals, nil)
+		pool, err := cluster.nodePool(node)
+		c.Expect(err, gospec.Equals, nil)
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		defer pool.Push(connection)
+
+		value, err := connection.Cmd("GET", "{user:1}:alice").Str()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value, gospec.Equals, "fresh")
+	})
+
+	c.Specify("[RedisClusterClient] Follows MOVED redirects, and updates the slot map", func() {
+		process, err := StartRedisCluster(logger, 2)
+		if nil != err {
+			panic(err)
+		}
+		defer process.Close()
+
+		cluster := &RedisCluster{Mode: LAZY, Size: 2, Urls: process.Urls(), Logger: logger}
+		defer cluster.Close()
+		c.Expect(cluster.Open(), gospec.Equals, nil)
+
+		// Move the key's empty slot to the other server
+		key := "moved:key"
+		slot := ClusterSlot(key)
+		url, _ := cluster.NodeOf(key)
+		source, target := otherServer(process, url)
+		for _, server := range []*RedisServerProcess{target, source} {
+			c.Expect(server.Connection().Cmd("CLUSTER", "SETSLOT", slot, "NODE", nodeId(target)).Err, gospec.Equals, nil)
+		}
+
+		client := MakeRedisClusterClient(cluster)
+		defer client.Close()
+		c.Expect(client.Cmd("SET", key, "value").Err, gospec.Equals, nil)
+
+		url, _ = cluster.NodeOf(key)
+		c.Expect(url, gospec.Equals, target.Url())
+
+		value, err := target.Connection().Cmd("GET", key).Str()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value, gospec.Equals, "value")
+	})
+
+	c.Specify("[RedisClusterClient] Follows ASK redirects while a slot is migrating", func() {
+		process, err := StartRedisCluster(logger, 2)
+		if nil != err {
+			panic(err)
+		}
+		defer process.Close()
+
+		cluster := &RedisCluster{Mode: LAZY, Size: 2, Urls: process.Urls(), Logger: logger}
+		defer cluster.Close()
+		c.Expect(cluster.Open(), gospec.Equals, nil)
+
+		// Start migrating the key's slot to the other server
+		key := "ask:key"
+		slot := ClusterSlot(key)
+		url, _ := cluster.NodeOf(key)
+		source, target := otherServer(process, url)
+		c.Expect(target.Connection().Cmd("CLUSTER", "SETSLOT", slot, "IMPORTING", nodeId(source)).Err, gospec.Equals, nil)
+		c.Expect(source.Connection().Cmd("CLUSTER", "SETSLOT", slot, "MIGRATING", nodeId(target)).Err, gospec.Equals, nil)
+
+		// The key isn't on the source, so the command is asked of the target
+		client := MakeRedisClusterClient(cluster)
+		defer client.Close()
+		c.Expect(client.Cmd("SET", key, "value").Err, gospec.Equals, nil)
+
+		value, err := client.Cmd("GET", key).Str()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value, gospec.Equals, "value")
+
+		// ASK doesn't change the slot map
+		url, _ = cluster.NodeOf(key)
+		c.Expect(url, gospec.Equals, source.Url())
+	})
+}
@@ -0,0 +1,70 @@
+//
+// Redis Command Table written in GO
+//
+
+package dog_pool
+
+import "strconv"
+import "strings"
+
+//
+// Commands that never write, and are safe to send to a replica
+//
+var redis_readonly_commands = map[string]bool{
+	// Keys
+	"EXISTS": true, "TTL": true, "PTTL": true, "TYPE": true, "KEYS": true, "SCAN": true, "RANDOMKEY": true, "DBSIZE": true, "DUMP": true, "EXPIRETIME": true,
+
+	// Strings & Bits
+	"GET": true, "MGET": true, "STRLEN": true, "GETRANGE": true, "SUBSTR": true, "GETBIT": true, "BITCOUNT": true, "BITPOS": true,
+
+	// Hashes
+	"HGET": true, "HMGET": true, "HGETALL": true, "HEXISTS": true, "HLEN": true, "HKEYS": true, "HVALS": true, "HSTRLEN": true, "HSCAN": true, "HRANDFIELD": true,
+
+	// Lists
+	"LLEN": true, "LRANGE": true, "LINDEX": true, "LPOS": true,
+
+	// Sets
+	"SCARD": true, "SISMEMBER": true, "SMISMEMBER": true, "SMEMBERS": true, "SRANDMEMBER": true, "SINTER": true, "SINTERCARD": true, "SUNION": true, "SDIFF": true, "SSCAN": true,
+
+	// Sorted Sets
+	"ZCARD": true, "ZCOUNT": true, "ZLEXCOUNT": true, "ZSCORE": true, "ZMSCORE": true, "ZRANK": true, "ZREVRANK": true, "ZSCAN": true, "ZRANDMEMBER": true,
+	"ZRANGE": true, "ZRANGEBYSCORE": true, "ZRANGEBYLEX": true, "ZREVRANGE": true, "ZREVRANGEBYSCORE": true, "ZREVRANGEBYLEX": true,
+
+	// Geo
+	"GEOPOS": true, "GEODIST": true, "GEOHASH": true, "GEOSEARCH": true,
+}
+
+//
+// Is the command read-only, so it can be sent to a replica?
+//
+func IsReadOnlyCommand(cmd string) bool {
+	return redis_readonly_commands[strings.ToUpper(cmd)]
+}
+
+//
+// Key the command is routed by, "" if the command has no key
+//
+// Used to pick the cluster node or the shard of the command.
+//
+func commandKey(cmd string, args []interface{}) string {
+	index := 0
+	switch strings.ToUpper(cmd) {
+	case cmd_bitop:
+		// BITOP operation destkey key [key ...]
+		index = 1
+	case "EVAL", "EVALSHA":
+		// EVAL script numkeys key [key ...] arg [arg ...]
+		if len(args) < 2 {
+			return ""
+		}
+		if numkeys, err := strconv.Atoi(argString(args[1])); nil != err || numkeys < 1 {
+			return ""
+		}
+		index = 2
+	}
+
+	if index >= len(args) {
+		return ""
+	}
+	return argString(args[index])
+}
@@ -0,0 +1,28 @@
+package dog_pool
+
+import "testing"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestRedisCommandsSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(RedisCommandsSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func RedisCommandsSpecs(c gospec.Context) {
+	c.Specify("[RedisCommands] Reads are read-only", func() {
+		for _, cmd := range []string{"GET", "MGET", "HGET", "HMGET", "HGETALL", "EXISTS", "HEXISTS", "GETBIT", "TTL", "mget", "HGetAll"} {
+			c.Expect(IsReadOnlyCommand(cmd), gospec.Equals, true)
+		}
+	})
+
+	c.Specify("[RedisCommands] Writes and unknown commands are not read-only", func() {
+		for _, cmd := range []string{"SET", "DEL", "HSET", "HINCRBY", "INCRBY", "SETBIT", "BITOP", "EXPIRE", "PERSIST", "EVAL", "SORT", "PING", "BOB"} {
+			c.Expect(IsReadOnlyCommand(cmd), gospec.Equals, false)
+		}
+	})
+}
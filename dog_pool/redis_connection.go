@@ -5,13 +5,15 @@
 package dog_pool
 
 import "bytes"
+import "context"
+import "crypto/tls"
 import "fmt"
+import "net"
 import "strings"
 import "time"
 import "reflect"
 import "strconv"
 import "github.com/RUNDSP/radix/redis"
-import "github.com/alecthomas/log4go"
 
 //
 // Connection Wrapper for Redis
@@ -21,35 +23,48 @@ type RedisConnection struct {
 
 	Id string "(optional) Identifier for distingushing between redis connections"
 
-	Logger *log4go.Logger "Handle to the logger we are using"
+	Logger Logger "(optional) Handle to the logger we are using, nil logs nothing"
 
-	Timeout time.Duration "Connection Timeout"
+	Timeout time.Duration "Connection Timeout, for dialing, reading and writing unless they have their own timeouts"
+
+	DialTimeout  time.Duration "(optional) Timeout for dialing Redis, defaults to Timeout"
+	ReadTimeout  time.Duration "(optional) Timeout for reading each reply, defaults to Timeout"
+	WriteTimeout time.Duration "(optional) Timeout for writing each command, defaults to Timeout"
+
+	Hooks *ConnectionHooks[*RedisConnection] "(optional) Callbacks for the connection's lifecycle events"
+
+	TLSConfig *tls.Config "(optional) TLS configuration for rediss:// urls, setting it dials the other TCP urls with TLS too"
+
+	Retry *RetryPolicy "(optional) Retry the read-only Cmd's that fail on network errors, nil disables the retries"
 
 	client *redis.Client "Connection to a Redis, may be nil"
 
+	conn *deadlineConn "Socket under the client, may be nil"
+
 	cmd_queue []string
+
+	stats *connectionStats "(optional) Counters shared with the pool, may be nil"
+
+	breaker *circuitBreaker "(optional) Circuit breaker shared by the connections to Url, may be nil"
 }
 
 func (p *RedisConnection) String() string {
-	return fmt.Sprintf("RedisConnection { Id=%v, Url=%v, Timeout=%v }", p.Id, p.Url, p.Timeout)
+	return fmt.Sprintf("RedisConnection { Id=%v, Url=%v, Timeout=%v }", p.Id, p.logUrl(), p.Timeout)
 }
 
-func minLogLevel(logger *log4go.Logger) log4go.Level {
-	log_level := log4go.CRITICAL
-	for _, filter := range *logger {
-		if log_level > filter.Level {
-			log_level = filter.Level
-		}
-	}
-	return log_level
+//
+// Logger to log to, NopLogger when Logger is nil
+//
+func (p *RedisConnection) logger() leveledLogger {
+	return logTo(p.Logger)
 }
 
 //
 // Lazily make a Redis Connection
 //
-func makeLazyRedisConnection(url string, id string, timeout time.Duration, logger *log4go.Logger) (*RedisConnection, error) {
+func makeLazyRedisConnection(url string, id string, timeouts connectionTimeouts, tls_config *tls.Config, logger Logger, stats *connectionStats, hooks *ConnectionHooks[*RedisConnection], breaker *circuitBreaker) (*RedisConnection, error) {
 	// Create a new factory instance
-	p := &RedisConnection{Url: url, Id: id, Logger: logger, Timeout: timeout}
+	p := &RedisConnection{Url: url, Id: id, Logger: logger, Timeout: timeouts.Timeout, DialTimeout: timeouts.Dial, ReadTimeout: timeouts.Read, WriteTimeout: timeouts.Write, TLSConfig: tls_config, Hooks: hooks, stats: stats, breaker: breaker}
 
 	// Return the factory
 	return p, nil
@@ -58,9 +73,9 @@ func makeLazyRedisConnection(url string, id string, timeout time.Duration, logge
 //
 // Agressively make a Redis Connection
 //
-func makeAgressiveRedisConnection(url string, id string, timeout time.Duration, logger *log4go.Logger) (*RedisConnection, error) {
+func makeAgressiveRedisConnection(url string, id string, timeouts connectionTimeouts, tls_config *tls.Config, logger Logger, stats *connectionStats, hooks *ConnectionHooks[*RedisConnection], breaker *circuitBreaker) (*RedisConnection, error) {
 	// Create a new factory instance
-	p, _ := makeLazyRedisConnection(url, id, timeout, logger)
+	p, _ := makeLazyRedisConnection(url, id, timeouts, tls_config, logger, stats, hooks, breaker)
 
 	// Ping the server
 	if err := p.Ping(); nil != err {
@@ -79,7 +94,8 @@ func makeAgressiveRedisConnection(url string, id string, timeout time.Duration,
 // Clone the connection and return a new instance of RedisConnection
 //
 func (p *RedisConnection) Clone() *RedisConnection {
-	connection, _ := makeLazyRedisConnection(p.Url, p.Id, p.Timeout, p.Logger)
+	connection, _ := makeLazyRedisConnection(p.Url, p.Id, p.timeouts(), p.TLSConfig, p.Logger, nil, p.Hooks, nil)
+	connection.Retry = p.Retry
 	return connection
 }
 
@@ -96,18 +112,23 @@ func (p *RedisConnection) Clone() *RedisConnection {
 //
 func (p *RedisConnection) Close() (err error) {
 	// Close the connection
-	if nil != p.client {
+	was_open := nil != p.client
+	if was_open {
 		err = p.client.Close()
 	}
 
 	// Set the pointer to nil
 	p.client = nil
+	p.conn = nil
 
 	// Log the event
-	if log4go.INFO >= minLogLevel(p.Logger) {
-		p.Logger.Info("[RedisConnection][Close][%s/%s] --> Closed!", p.Url, p.Id)
+	if p.logger().Enabled(LOG_INFO) {
+		p.logger().Info("[RedisConnection][Close][%s/%s] --> Closed!", p.logUrl(), p.Id)
 	}
 
+	if was_open {
+		p.Hooks.closed(p)
+	}
 	return
 }
 
@@ -117,12 +138,35 @@ func (p *RedisConnection) Close() (err error) {
 // - Returns GetReply()
 //
 func (p *RedisConnection) Cmd(cmd string, args ...interface{}) *redis.Reply {
+	return p.CmdContext(context.Background(), cmd, args...)
+}
+
+//
+// CmdContext calls the given Redis command, within the context's deadline:
+// - Calls Append(...)
+// - Returns GetReplyContext(ctx)
+// - Read-only commands are retried on network errors, if the connection has a Retry policy
+//
+func (p *RedisConnection) CmdContext(ctx context.Context, cmd string, args ...interface{}) *redis.Reply {
 	stop_watch := MakeStopWatch(p, p.Logger, strings.Join([]string{"Cmd", cmd}, " ")).Start()
-	defer stop_watch.LogDurationAt(log4go.TRACE)
+	defer stop_watch.LogDurationAt(LOG_TRACE)
 	defer stop_watch.Stop()
 
-	p.Append(cmd, args...)
-	return p.GetReply()
+	if !IsReadOnlyCommand(cmd) {
+		p.Append(cmd, args...)
+		return p.GetReplyContext(ctx)
+	}
+
+	var reply *redis.Reply
+	p.Retry.do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			p.logger().Warn("[RedisConnection][Cmd][%s/%s] Retrying %s, attempt=%d, Error = %v", p.logUrl(), p.Id, cmd, attempt, reply.Err)
+		}
+		p.Append(cmd, args...)
+		reply = p.GetReplyContext(ctx)
+		return reply.Err
+	})
+	return reply
 }
 
 //
@@ -131,29 +175,29 @@ func (p *RedisConnection) Cmd(cmd string, args ...interface{}) *redis.Reply {
 //
 func (p *RedisConnection) Append(cmd string, args ...interface{}) {
 	var last_cmd string
-	if log4go.INFO >= minLogLevel(p.Logger) {
+	if p.logger().Enabled(LOG_INFO) {
 		last_cmd := string(formatArgs(cmd, args))
 		p.cmd_queue = append(p.cmd_queue, last_cmd)
 	}
 
 	// Wrap in a lambda to prevent evaulation, unless logging is enabled ...
-	if log4go.TRACE >= minLogLevel(p.Logger) {
-		p.Logger.Trace("[RedisConnection][Append][%s/%s] Redis Command = '%s'", p.Url, p.Id, last_cmd)
+	if p.logger().Enabled(LOG_TRACE) {
+		p.logger().Trace("[RedisConnection][Append][%s/%s] Redis Command = '%s'", p.logUrl(), p.Id, last_cmd)
 	}
 
 	// If the connection is not open, then open it
 	if !p.IsOpen() {
 		// Did opening the connection fail?
 		if err := p.Open(); nil != err {
-			p.Logger.Warn("[RedisConnection][Append][%s/%s] Redis Command = '%s' --> Error = %v", p.Url, p.Id, last_cmd, err)
+			p.logger().Warn("[RedisConnection][Append][%s/%s] Redis Command = '%s' --> Error = %v", p.logUrl(), p.Id, last_cmd, err)
 			return
 		}
 	}
 
 	// Append the command
-	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "Append", cmd}).Start()
+	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.logUrl(), p.Id, "Append", cmd}).Start()
 	p.client.Append(cmd, args...)
-	stop_watch.Stop().LogDurationAt(log4go.FINEST)
+	stop_watch.Stop().LogDurationAt(LOG_FINEST)
 }
 
 //
@@ -162,15 +206,34 @@ func (p *RedisConnection) Append(cmd string, args ...interface{}) {
 // if the pipeline queue is empty.
 //
 func (p *RedisConnection) GetReply() *redis.Reply {
+	return p.GetReplyContext(context.Background())
+}
+
+//
+// GetReplyContext returns the reply for the next request in the pipeline queue,
+// within the context's deadline.
+// Cancelling the context aborts the read, and closes the connection that is now out of sync;
+// the error reply has the context's error.
+//
+func (p *RedisConnection) GetReplyContext(ctx context.Context) *redis.Reply {
 	// Connection is closed?
 	if !p.IsOpen() {
 		return &redis.Reply{Type: redis.ErrorReply, Err: ErrConnectionIsClosed}
 	}
 
 	// Get the reply from redis
-	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.Url, p.Id, "GetReply"}).Start()
-	reply := p.client.GetReply()
-	stop_watch.Stop().LogDurationAt(log4go.FINEST)
+	stop_watch := MakeStopWatchTags(p, p.Logger, []string{p.logUrl(), p.Id, "GetReply"}).Start()
+	var reply *redis.Reply
+	var ctx_err error
+	if ctx_err = ctx.Err(); nil == ctx_err {
+		stop := p.conn.watch(ctx)
+		reply = p.client.GetReply()
+		if ctx_err = stop(); nil == ctx_err && isTimeout(reply.Err) {
+			// The read may time out at the context's deadline before the context notices
+			ctx_err = pastDeadlineErr(ctx)
+		}
+	}
+	stop_watch.Stop().LogDurationAt(LOG_FINEST)
 
 	var first_cmd string
 	switch {
@@ -182,6 +245,20 @@ func (p *RedisConnection) GetReply() *redis.Reply {
 		p.cmd_queue = p.cmd_queue[1:]
 	}
 
+	// Aborted by the context, Redis is fine but the connection is out of sync
+	if nil != ctx_err {
+		p.logger().Warn("[RedisConnection][GetReply][%s/%s] Aborted, cmd=%v, Error = %v", p.logUrl(), p.Id, first_cmd, ctx_err)
+		p.Close()
+		return &redis.Reply{Type: redis.ErrorReply, Err: ctx_err}
+	}
+
+	// MOVED/ASK redirects are followed by the RedisClusterClient, the connection is fine
+	if reply.Type == redis.ErrorReply && isClusterRedirect(reply.Err) {
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redirected by Redis, cmd=%v, Error = %v", p.logUrl(), p.Id, first_cmd, reply.Err)
+		p.breaker.success()
+		return reply
+	}
+
 	// If the connection
 	if reply.Type == redis.ErrorReply {
 		//* Common errors
@@ -194,16 +271,21 @@ func (p *RedisConnection) GetReply() *redis.Reply {
 			fallthrough
 		case redis.PipelineQueueEmptyError.Error():
 			// Log the error & break
-			p.Logger.Warn("[RedisConnection][GetReply][%s/%s] Ignored Error from Redis, cmd=%v, Error = %v", p.Url, p.Id, first_cmd, reply.Err)
+			p.logger().Warn("[RedisConnection][GetReply][%s/%s] Ignored Error from Redis, cmd=%v, Error = %v", p.logUrl(), p.Id, first_cmd, reply.Err)
+			p.breaker.success()
 			break
 
 		default:
 			// All other errors are fatal!
 			// Close the connection and log the error
-			p.Logger.Error("[RedisConnection][GetReply][%s/%s] Fatal Error from Redis, cmd=%v, Error = %v", p.Url, p.Id, first_cmd, reply.Err)
+			p.logger().Error("[RedisConnection][GetReply][%s/%s] Fatal Error from Redis, cmd=%v, Error = %v", p.logUrl(), p.Id, first_cmd, reply.Err)
+			p.stats.addFatalError()
+			p.breaker.failure()
+			p.Hooks.fatalError(p, reply.Err)
 			p.Close()
 		}
 	} else {
+		p.breaker.success()
 		p.logReply(first_cmd, "root", reply)
 	}
 
@@ -212,7 +294,7 @@ func (p *RedisConnection) GetReply() *redis.Reply {
 }
 
 func (p *RedisConnection) logReply(cmd, reply_depth string, reply *redis.Reply) {
-	if log4go.INFO < minLogLevel(p.Logger) {
+	if !p.logger().Enabled(LOG_INFO) {
 		return
 	}
 
@@ -220,36 +302,36 @@ func (p *RedisConnection) logReply(cmd, reply_depth string, reply *redis.Reply)
 	switch reply.Type {
 	case redis.StatusReply:
 		b, _ := reply.Bool()
-		p.Logger.Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=StatusReply, Reply.Status=%v", p.Url, p.Id, reply_depth, cmd, b)
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=StatusReply, Reply.Status=%v", p.logUrl(), p.Id, reply_depth, cmd, b)
 		return
 
 	case redis.ErrorReply:
-		p.Logger.Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=ErrorReply, Reply.Err=%v", p.Url, p.Id, reply_depth, cmd, reply.Err)
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=ErrorReply, Reply.Err=%v", p.logUrl(), p.Id, reply_depth, cmd, reply.Err)
 		return
 
 	case redis.IntegerReply:
 		i, _ := reply.Int64()
-		p.Logger.Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=IntegerReply, Reply.Int=%v", p.Url, p.Id, reply_depth, cmd, i)
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=IntegerReply, Reply.Int=%v", p.logUrl(), p.Id, reply_depth, cmd, i)
 		return
 
 	case redis.NilReply:
-		p.Logger.Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=NilReply", p.Url, p.Id, reply_depth, cmd)
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=NilReply", p.logUrl(), p.Id, reply_depth, cmd)
 		return
 
 	case redis.BulkReply:
 		b, _ := reply.Bytes()
-		p.Logger.Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=BulkReply, Reply.Str=%v, Reply.Bytes=%v", p.Url, p.Id, reply_depth, cmd, string(b), b)
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=BulkReply, Reply.Str=%v, Reply.Bytes=%v", p.logUrl(), p.Id, reply_depth, cmd, string(b), b)
 		return
 
 	case redis.MultiReply:
-		p.Logger.Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=MultiReply, Reply.Elems.Count=%v", p.Url, p.Id, reply_depth, cmd, len(reply.Elems))
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply.Type=MultiReply, Reply.Elems.Count=%v", p.logUrl(), p.Id, reply_depth, cmd, len(reply.Elems))
 		for i, elem := range reply.Elems {
 			p.logReply(cmd, fmt.Sprintf("%s->%d", reply_depth, i), elem)
 		}
 		return
 
 	default:
-		p.Logger.Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply=%#v", p.Url, p.Id, reply_depth, cmd, reply)
+		p.logger().Info("[RedisConnection][GetReply][%s/%s] Redis Reply[%v], Cmd=%v, Reply=%#v", p.logUrl(), p.Id, reply_depth, cmd, reply)
 		return
 	}
 }
@@ -341,12 +423,12 @@ func (p *RedisConnection) HashFieldsExist(key string, fields ...string) ([]bool,
 func (p *RedisConnection) Client() (*redis.Client, error) {
 	// Is a saved connection available?
 	if p.IsOpen() {
-		p.Logger.Trace("[RedisConnection][Client][%s/%s] --> Found Opened Connection!", p.Url, p.Id)
+		p.logger().Trace("[RedisConnection][Client][%s/%s] --> Found Opened Connection!", p.logUrl(), p.Id)
 
 		// Return the connection
 		return p.client, nil
 	} else {
-		p.Logger.Warn("[RedisConnection][Client][%s/%s] --> Found Closed Connection!", p.Url, p.Id)
+		p.logger().Warn("[RedisConnection][Client][%s/%s] --> Found Closed Connection!", p.logUrl(), p.Id)
 	}
 
 	// Open a new connection to redis
@@ -376,7 +458,7 @@ func (p *RedisConnection) IsOpen() bool {
 	output := nil != p.client
 
 	// Debug logging
-	p.Logger.Trace("[RedisConnection][IsOpen][%s/%s] --> %v", p.Url, p.Id, output)
+	p.logger().Trace("[RedisConnection][IsOpen][%s/%s] --> %v", p.logUrl(), p.Id, output)
 
 	return output
 }
@@ -388,7 +470,7 @@ func (p *RedisConnection) IsClosed() bool {
 	output := nil == p.client
 
 	// Debug logging
-	p.Logger.Trace("[RedisConnection][IsClosed][%s/%s] --> %v", p.Url, p.Id, output)
+	p.logger().Trace("[RedisConnection][IsClosed][%s/%s] --> %v", p.logUrl(), p.Id, output)
 
 	return output
 }
@@ -399,33 +481,158 @@ func (p *RedisConnection) IsClosed() bool {
 func (p *RedisConnection) Open() error {
 	// Set the default timeout
 	if time.Duration(0) == p.Timeout {
-		p.Timeout = time.Duration(10) * time.Second
+		p.Timeout = default_timeout
 	}
 
-	// Open the TCP connection
-	client, err := redis.DialTimeout("tcp", p.Url, p.Timeout)
+	// Parse the redis:// url
+	redis_url, err := ParseRedisUrl(p.Url)
+	if nil != err {
+		p.logger().Error("[RedisConnection][Open][%s/%s] --> Error = %v", p.logUrl(), p.Id, err)
+		return err
+	}
+	timeouts := p.timeouts()
+	if time.Duration(0) != redis_url.Timeout {
+		timeouts.Timeout = redis_url.Timeout
+	}
+
+	// Fail fast while the circuit breaker is open
+	ok, probe := p.breaker.allow()
+	if !ok {
+		p.logger().Warn("[RedisConnection][Open][%s/%s] --> Error = %v", p.logUrl(), p.Id, ErrCircuitOpen)
+		return ErrCircuitOpen
+	}
+
+	// Open the TCP, TLS or Unix socket connection
+	client, conn, err := p.dial(redis_url, timeouts)
 
 	// Check for errors
 	if nil != err {
 		// Log the event
-		p.Logger.Error("[RedisConnection][Open][%s/%s] --> Error = %v", p.Url, p.Id, err)
+		p.logger().Error("[RedisConnection][Open][%s/%s] --> Error = %v", p.logUrl(), p.Id, err)
+		p.stats.addDialError()
+		p.breaker.failure()
 
 		// Return the error
 		return err
 	}
 
+	// Authenticate, select the database and name the connection, on every re-connect
+	if err := p.handshake(client, redis_url); nil != err {
+		p.logger().Error("[RedisConnection][Open][%s/%s] --> Handshake Error = %v", p.logUrl(), p.Id, err)
+		client.Close()
+		p.stats.addDialError()
+		p.breaker.failure()
+		return err
+	}
+
 	// Save the client pointer
 	p.client = client
+	p.conn = conn
 
 	// Log the event
-	if log4go.INFO >= minLogLevel(p.Logger) {
-		p.Logger.Info("[RedisConnection][Open][%s/%s] --> Opened!", p.Url, p.Id)
+	if p.logger().Enabled(LOG_INFO) {
+		p.logger().Info("[RedisConnection][Open][%s/%s] --> Opened!", p.logUrl(), p.Id)
+	}
+
+	// Let the hooks prepare the connection, closing it on errors
+	if err := p.Hooks.opened(p); nil != err {
+		p.logger().Error("[RedisConnection][Open][%s/%s] --> OnOpen Error = %v", p.logUrl(), p.Id, err)
+		p.Close()
+		return err
+	}
+
+	// Probe the url before closing the circuit breaker,
+	// GetReply records the success or the failure
+	if probe {
+		if err := p.Ping(); nil != err && p.IsClosed() {
+			p.logger().Error("[RedisConnection][Open][%s/%s] --> Probe Error = %v", p.logUrl(), p.Id, err)
+			return err
+		}
 	}
 
 	// Return nil
 	return nil
 }
 
+//
+// Timeouts of the connection, the Dial, Read and Write timeouts fall back to Timeout
+//
+func (p *RedisConnection) timeouts() connectionTimeouts {
+	return connectionTimeouts{Timeout: p.Timeout, Dial: p.DialTimeout, Read: p.ReadTimeout, Write: p.WriteTimeout}
+}
+
+//
+// Dial the url's network, with TLS for rediss:// urls or when TLSConfig is set
+//
+func (p *RedisConnection) dial(redis_url *RedisUrl, timeouts connectionTimeouts) (*redis.Client, *deadlineConn, error) {
+	dialer := &net.Dialer{Timeout: timeouts.dial()}
+
+	var conn net.Conn
+	var err error
+	if "tcp" != redis_url.Network || (!redis_url.TLS && nil == p.TLSConfig) {
+		conn, err = dialer.Dial(redis_url.Network, redis_url.Addr)
+	} else {
+		config := p.TLSConfig
+		if nil == config {
+			config = &tls.Config{}
+		}
+		if "" == config.ServerName {
+			config = config.Clone()
+			config.ServerName, _, _ = net.SplitHostPort(redis_url.Addr)
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", redis_url.Addr, config)
+	}
+	if nil != err {
+		if isTimeout(err) {
+			err = &TimeoutError{Op: "dial", After: timeouts.dial(), Err: err}
+		}
+		return nil, nil, err
+	}
+
+	// Apply the read/write timeouts, and the contexts, on the socket
+	deadline_conn := &deadlineConn{Conn: conn, readTimeout: timeouts.read(), writeTimeout: timeouts.write()}
+	client, err := redis.NewClient(deadline_conn)
+	if nil != err {
+		conn.Close()
+		return nil, nil, err
+	}
+	return client, deadline_conn, nil
+}
+
+//
+// Send the AUTH, SELECT and CLIENT SETNAME the url asks for
+//
+// redis://, rediss:// and unix:// urls name the connection after its Id, unless they have a name option.
+// The commands go straight to the client, so the password is never logged.
+//
+func (p *RedisConnection) handshake(client *redis.Client, redis_url *RedisUrl) error {
+	cmds := [][]interface{}{}
+	switch {
+	case "" != redis_url.Username && "" != redis_url.Password:
+		cmds = append(cmds, []interface{}{"AUTH", redis_url.Username, redis_url.Password})
+	case "" != redis_url.Password:
+		cmds = append(cmds, []interface{}{"AUTH", redis_url.Password})
+	}
+	if 0 != redis_url.Database {
+		cmds = append(cmds, []interface{}{"SELECT", redis_url.Database})
+	}
+
+	name := redis_url.Name
+	if "" == name && isRedisUrl(p.Url) {
+		name = p.Id
+	}
+	if "" != name {
+		cmds = append(cmds, []interface{}{"CLIENT", "SETNAME", name})
+	}
+
+	for _, cmd := range cmds {
+		if reply := client.Cmd(cmd[0].(string), cmd[1:]...); nil != reply.Err {
+			return fmt.Errorf("%v failed, Error = %v", cmd[0], reply.Err)
+		}
+	}
+	return nil
+}
+
 // formatArg formats the given argument to a Redis-styled argument byte slice.
 func formatArg(v interface{}) []byte {
 	var b, bs []byte
@@ -1,9 +1,14 @@
 package dog_pool
 
+import "context"
 import "fmt"
+import "os"
+import "path/filepath"
+import "strings"
 import "testing"
+import "time"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
-import "github.com/alecthomas/log4go"
 
 func TestRedisConnectionSpecs(t *testing.T) {
 	if testing.Short() {
@@ -17,10 +22,10 @@ func TestRedisConnectionSpecs(t *testing.T) {
 
 // Helpers
 func RedisConnectionSpecs(c gospec.Context) {
-	var redis_connection_logger = log4go.NewDefaultLogger(log4go.CRITICAL)
+	var redis_connection_logger = MakeStdLogger(log.Default(), LOG_CRITICAL)
 
 	c.Specify("[RedisConnection] Clone a connection", func() {
-		connection := &RedisConnection{Url: "127.0.0.1:6990", Id: "Bob", Logger: &redis_connection_logger}
+		connection := &RedisConnection{Url: "127.0.0.1:6990", Id: "Bob", Logger: redis_connection_logger}
 		defer connection.Close()
 		c.Expect(connection.IsOpen(), gospec.Equals, false)
 
@@ -33,7 +38,7 @@ func RedisConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisConnection] New connection is not open", func() {
-		connection := RedisConnection{Url: "127.0.0.1:6990", Logger: &redis_connection_logger}
+		connection := RedisConnection{Url: "127.0.0.1:6990", Logger: redis_connection_logger}
 		defer connection.Close()
 
 		open := connection.IsOpen()
@@ -46,7 +51,7 @@ func RedisConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisConnection] Opening connection to Invalid Host/Port has errors", func() {
-		connection := RedisConnection{Url: "127.0.0.1:6991", Logger: &redis_connection_logger}
+		connection := RedisConnection{Url: "127.0.0.1:6991", Logger: redis_connection_logger}
 		defer connection.Close()
 
 		// The server is not running ...
@@ -58,9 +63,22 @@ func RedisConnectionSpecs(c gospec.Context) {
 		c.Expect(closed, gospec.Equals, true)
 	})
 
+	c.Specify("[RedisConnection] Defaults to the same Timeout as the pools", func() {
+		connection := RedisConnection{Url: "127.0.0.1:6991", Logger: redis_connection_logger}
+		defer connection.Close()
+		connection.Open()
+
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_connection_logger}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		c.Expect(connection.Timeout, gospec.Equals, default_timeout)
+		c.Expect(pool.Timeout, gospec.Equals, default_timeout)
+	})
+
 	c.Specify("[RedisConnection] Opening connection to Valid Host/Port has no errors", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -72,8 +90,8 @@ func RedisConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisConnection][KeysExist] Checks if keys exists", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, server_err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, server_err := StartRedisServer(logger)
 		if nil != server_err {
 			panic(server_err)
 		}
@@ -102,8 +120,8 @@ func RedisConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisConnection][HashFieldsExist] Checks if keys exists", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, server_err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, server_err := StartRedisServer(logger)
 		if nil != server_err {
 			panic(server_err)
 		}
@@ -140,8 +158,8 @@ func RedisConnectionSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisConnection] Ping (-->Cmd-->Append+GetReply) (re-)opens the connection automatically", func() {
-		logger := log4go.NewDefaultLogger(log4go.INFO)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_INFO)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -167,11 +185,198 @@ func RedisConnectionSpecs(c gospec.Context) {
 		c.Expect(server.Connection().IsClosed(), gospec.Equals, false)
 	})
 
+	c.Specify("[RedisConnection] Hooks are called when the connection opens and closes", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		opened, closed := 0, 0
+		connection := &RedisConnection{Url: server.Connection().Url, Logger: logger, Hooks: &ConnectionHooks[*RedisConnection]{
+			OnOpen:  func(*RedisConnection) error { opened++; return nil },
+			OnClose: func(*RedisConnection) { closed++ },
+		}}
+
+		c.Expect(connection.Open(), gospec.Equals, nil)
+		c.Expect(opened, gospec.Equals, 1)
+
+		connection.Close()
+		connection.Close()
+		c.Expect(closed, gospec.Equals, 1)
+	})
+
+	c.Specify("[RedisConnection] OnOpen errors close the connection", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		expected := fmt.Errorf("OnOpen Error")
+		connection := &RedisConnection{Url: server.Connection().Url, Logger: logger, Hooks: &ConnectionHooks[*RedisConnection]{
+			OnOpen: func(*RedisConnection) error { return expected },
+		}}
+
+		c.Expect(connection.Open(), gospec.Equals, expected)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnection] OnFatalError is called when a fatal error closes the connection", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+
+		var fatal error
+		connection := &RedisConnection{Url: server.Connection().Url, Logger: logger, Hooks: &ConnectionHooks[*RedisConnection]{
+			OnFatalError: func(_ *RedisConnection, err error) { fatal = err },
+		}}
+		c.Expect(connection.Open(), gospec.Equals, nil)
+
+		// Stop the server under the open connection
+		server.Close()
+		connection.Cmd("PING")
+		c.Expect(fatal, gospec.Satisfies, nil != fatal)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnection][CmdContext] Cancelling the context aborts the command and closes the connection", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		connection := server.Connection()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(time.Duration(100)*time.Millisecond, cancel)
+
+		// Blocks until the context is cancelled
+		reply := connection.CmdContext(ctx, "BLPOP", "bob", 0)
+		c.Expect(reply.Err, gospec.Equals, context.Canceled)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+
+		// Cancelled before the command is sent
+		reply = connection.CmdContext(ctx, "PING")
+		c.Expect(reply.Err, gospec.Equals, context.Canceled)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+
+		// Re-opens with a new context
+		reply = connection.CmdContext(context.Background(), "PING")
+		c.Expect(reply.Err, gospec.Equals, nil)
+	})
+
+	c.Specify("[RedisConnection][CmdContext] The context's deadline aborts the command", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(100)*time.Millisecond)
+		defer cancel()
+
+		reply := server.Connection().CmdContext(ctx, "BLPOP", "bob", 0)
+		c.Expect(reply.Err, gospec.Equals, context.DeadlineExceeded)
+		c.Expect(server.Connection().IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnection] ReadTimeout closes the connection with a TimeoutError", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		connection := &RedisConnection{Url: server.Url(), Logger: logger, ReadTimeout: time.Duration(100) * time.Millisecond}
+		defer connection.Close()
+
+		// Blocks longer than the ReadTimeout
+		reply := connection.Cmd("BLPOP", "bob", 0)
+		timeout_err, ok := reply.Err.(*TimeoutError)
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(*timeout_err, gospec.Satisfies, "read" == timeout_err.Op && time.Duration(100)*time.Millisecond == timeout_err.After)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+
+		// Re-opens, commands within the ReadTimeout are fine
+		c.Expect(connection.Ping(), gospec.Equals, nil)
+	})
+
+	c.Specify("[RedisConnection] Connects to rediss:// urls with TLS", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		dir, err := os.MkdirTemp("", "dog_pool")
+		if nil != err {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		cert, err := MakeSelfSignedCert(dir)
+		if nil != err {
+			panic(err)
+		}
+		server, err := startRedisServer(logger, func(port int) ([]string, error) {
+			return []string{"--port", "0", "--tls-port", fmt.Sprintf("%d", port),
+				"--tls-cert-file", cert.CertFile, "--tls-key-file", cert.KeyFile, "--tls-ca-cert-file", cert.CertFile, "--tls-auth-clients", "no"}, nil
+		})
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		// rediss:// -or- a bare url with a TLSConfig
+		for _, connection := range []*RedisConnection{
+			&RedisConnection{Url: "rediss://" + server.Url() + "/2", Logger: logger, TLSConfig: cert.ClientConfig},
+			&RedisConnection{Url: server.Url(), Logger: logger, TLSConfig: cert.ClientConfig},
+		} {
+			c.Expect(connection.Ping(), gospec.Equals, nil)
+			connection.Close()
+		}
+
+		// The certificate isn't trusted without the TLSConfig
+		connection := &RedisConnection{Url: "rediss://" + server.Url(), Logger: logger}
+		defer connection.Close()
+		err = connection.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+	})
+
+	c.Specify("[RedisConnection] Connects to unix:// urls", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		dir, err := os.MkdirTemp("", "dog_pool")
+		if nil != err {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		socket := filepath.Join(dir, "redis.sock")
+		server, err := startRedisServer(logger, func(port int) ([]string, error) {
+			return []string{"--port", "0", "--unixsocket", socket}, nil
+		})
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		connection := &RedisConnection{Url: "unix://" + socket + "?db=2", Id: "Bob", Logger: logger}
+		defer connection.Close()
+		c.Expect(connection.Ping(), gospec.Equals, nil)
+
+		info, err := connection.Cmd("CLIENT", "INFO").Str()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(info, gospec.Satisfies, strings.Contains(info, " db=2 "))
+	})
+
 }
 
 func Benchmark_Get_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -186,8 +391,8 @@ func Benchmark_Get_RedisConnection(b *testing.B) {
 }
 
 func Benchmark_Set_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -200,8 +405,8 @@ func Benchmark_Set_RedisConnection(b *testing.B) {
 }
 
 func Benchmark_Del_CacheMiss_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -214,8 +419,8 @@ func Benchmark_Del_CacheMiss_RedisConnection(b *testing.B) {
 }
 
 func Benchmark_SetGet_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -229,8 +434,8 @@ func Benchmark_SetGet_RedisConnection(b *testing.B) {
 }
 
 func Benchmark_Bit_Get_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -247,8 +452,8 @@ func Benchmark_Bit_Get_RedisConnection(b *testing.B) {
 }
 
 func Benchmark_BitOp_And_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -268,8 +473,8 @@ func Benchmark_BitOp_And_RedisConnection(b *testing.B) {
 }
 
 func Benchmark_BitOp_Or_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -289,8 +494,8 @@ func Benchmark_BitOp_Or_RedisConnection(b *testing.B) {
 }
 
 func Benchmark_BitOp_Not_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -315,8 +520,8 @@ func Benchmark_BitOp_Not_RedisConnection(b *testing.B) {
 
 // Pre-format the commands as a single string, and compare the results to above
 func Benchmark_BitOp_ComplementSet_RedisConnection(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -0,0 +1,51 @@
+//
+// Redis Client bound to a context written in GO
+//
+
+package dog_pool
+
+import "context"
+import "github.com/RUNDSP/radix/redis"
+
+//
+// Redis Client that calls Cmd and GetReply within the context's deadline
+//
+// Clients without the context-aware methods get the context checked
+// before each Cmd and GetReply, the calls themselves can't be interrupted.
+//
+type contextRedisClient struct {
+	RedisClientInterface
+	ctx context.Context
+}
+
+//
+// Bind the client to the context, replacing the context it was bound to
+//
+func withRedisContext(ctx context.Context, client RedisClientInterface) RedisClientInterface {
+	if bound, ok := client.(*contextRedisClient); ok {
+		client = bound.RedisClientInterface
+	}
+	return &contextRedisClient{RedisClientInterface: client, ctx: ctx}
+}
+
+func (p *contextRedisClient) Cmd(cmd string, args ...interface{}) *redis.Reply {
+	if client, ok := p.RedisClientInterface.(RedisContextClientInterface); ok {
+		return client.CmdContext(p.ctx, cmd, args...)
+	}
+	if err := p.ctx.Err(); nil != err {
+		return &redis.Reply{Type: redis.ErrorReply, Err: err}
+	}
+	return p.RedisClientInterface.Cmd(cmd, args...)
+}
+
+func (p *contextRedisClient) GetReply() *redis.Reply {
+	if client, ok := p.RedisClientInterface.(RedisContextClientInterface); ok {
+		return client.GetReplyContext(p.ctx)
+	}
+	if err := p.ctx.Err(); nil != err {
+		// Read the reply anyway, to keep the pipeline in sync
+		p.RedisClientInterface.GetReply()
+		return &redis.Reply{Type: redis.ErrorReply, Err: err}
+	}
+	return p.RedisClientInterface.GetReply()
+}
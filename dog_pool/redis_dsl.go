@@ -1,5 +1,6 @@
 package dog_pool
 
+import "context"
 import "fmt"
 import "github.com/RUNDSP/radix/redis"
 
@@ -10,6 +11,24 @@ type RedisDsl struct {
 	RedisClientInterface
 }
 
+//
+// Copy of the DSL that calls the commands within the context's deadline
+//
+// Cancelling the context aborts the command, and closes the connection.
+//
+func (p RedisDsl) WithContext(ctx context.Context) RedisDsl {
+	return RedisDsl{withRedisContext(ctx, p.RedisClientInterface)}
+}
+
+//
+// Copy of the DSL that retries the read-only commands that fail on network errors
+//
+// Only single commands are retried, KEYS_EXIST, HASH_FIELDS_EXIST, GETBITS and the HASHES_* operations are pipelined.
+//
+func (p RedisDsl) WithRetry(policy *RetryPolicy) RedisDsl {
+	return RedisDsl{withRedisRetry(policy, p.RedisClientInterface)}
+}
+
 //
 // ==================================================
 //
@@ -1,10 +1,11 @@
 package dog_pool
 
+import "context"
 import "fmt"
 import "math"
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
-import "github.com/alecthomas/log4go"
 import "github.com/RUNDSP/radix/redis"
 
 func TestRedisDslSpecs(t *testing.T) {
@@ -28,9 +29,32 @@ func RedisDslSpecs(c gospec.Context) {
 	// ==================================================
 	//
 
+	c.Specify("[RedisDsl][WithContext] Calls the commands within the context's deadline", func() {
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		dsl := RedisDsl{server.Connection()}.WithContext(ctx)
+		value, err := dsl.KEY_EXISTS("Miss")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value, gospec.Equals, false)
+
+		cancel()
+		_, err = dsl.KEY_EXISTS("Miss")
+		c.Expect(err, gospec.Equals, context.Canceled)
+
+		// Replaces the cancelled context
+		_, err = dsl.WithContext(context.Background()).KEY_EXISTS("Miss")
+		c.Expect(err, gospec.Equals, nil)
+	})
+
 	c.Specify("[RedisDsl][KEY_EXISTS]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -52,8 +76,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][KEYS_EXIST]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -74,8 +98,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_FIELD_EXISTS]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -105,8 +129,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_FIELDS_EXIST]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -127,8 +151,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_FIELD_EXISTS]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -157,8 +181,8 @@ func RedisDslSpecs(c gospec.Context) {
 	//
 
 	c.Specify("[RedisDsl][INCR]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -176,8 +200,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][INCRBY]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -195,8 +219,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][INCRBYFLOAT]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -214,8 +238,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][DECR]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -233,8 +257,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][DECRBY]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -252,8 +276,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][DECRBYFLOAT]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -271,8 +295,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_INCR]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -290,8 +314,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_INCRBY]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -309,8 +333,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_INCRBYFLOAT]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -328,8 +352,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_DECR]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -347,8 +371,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_DECRBY]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -366,8 +390,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_DECRBYFLOAT]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -394,8 +418,8 @@ func RedisDslSpecs(c gospec.Context) {
 	//
 
 	c.Specify("[RedisDsl][GET]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -421,8 +445,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][GET_STRING]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -444,8 +468,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][GET_INT64]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -467,8 +491,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][GET_FLOAT64]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -490,8 +514,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][MGET]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -538,8 +562,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][MGET_STRINGS]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -567,8 +591,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][MGET_INT64S]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -596,8 +620,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][MGET_FLOAT64S]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -625,8 +649,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][GETBIT]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -653,8 +677,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][GETBITS]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -712,8 +736,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][GETBITS_TURNED_ON]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -770,8 +794,8 @@ func RedisDslSpecs(c gospec.Context) {
 	//
 
 	c.Specify("[RedisDsl][HASH_GET]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -797,8 +821,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_GET_STRING]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -820,8 +844,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_GET_INT64]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -843,8 +867,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_GET_FLOAT64]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -866,8 +890,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_MGET]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -914,8 +938,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_MGET_STRINGS]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -943,8 +967,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_MGET_INT64S]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -972,8 +996,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASH_MGET_FLOAT64S]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1010,8 +1034,8 @@ func RedisDslSpecs(c gospec.Context) {
 	//
 
 	c.Specify("[RedisDsl][HASHES_GET]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1059,8 +1083,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_GET_STRING]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1092,8 +1116,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_GET_INT64]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1125,8 +1149,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_GET_FLOAT64]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1158,8 +1182,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_MGET]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1208,8 +1232,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_MGET_STRINGS]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1248,8 +1272,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_MGET_INT64S]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1288,8 +1312,8 @@ func RedisDslSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[RedisDsl][HASHES_MGET_FLOAT64S]", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		server, err := StartRedisServer(&logger)
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		server, err := StartRedisServer(logger)
 		if nil != err {
 			panic(err)
 		}
@@ -1339,8 +1363,8 @@ func RedisDslSpecs(c gospec.Context) {
 //
 
 func Benchmark_RedisDsl_KEY_EXISTS(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1356,8 +1380,8 @@ func Benchmark_RedisDsl_KEY_EXISTS(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_KEYS_EXIST(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1373,8 +1397,8 @@ func Benchmark_RedisDsl_KEYS_EXIST(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_FIELD_EXISTS(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1390,8 +1414,8 @@ func Benchmark_RedisDsl_HASH_FIELD_EXISTS(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_FIELDS_EXIST(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1415,8 +1439,8 @@ func Benchmark_RedisDsl_HASH_FIELDS_EXIST(b *testing.B) {
 //
 
 func Benchmark_RedisDsl_INCR(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1432,8 +1456,8 @@ func Benchmark_RedisDsl_INCR(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_INCRBY(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1449,8 +1473,8 @@ func Benchmark_RedisDsl_INCRBY(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_INCRBYFLOAT(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1466,8 +1490,8 @@ func Benchmark_RedisDsl_INCRBYFLOAT(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_DECR(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1483,8 +1507,8 @@ func Benchmark_RedisDsl_DECR(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_DECRBY(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1500,8 +1524,8 @@ func Benchmark_RedisDsl_DECRBY(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_DECRBYFLOAT(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1517,8 +1541,8 @@ func Benchmark_RedisDsl_DECRBYFLOAT(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_INCR(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1534,8 +1558,8 @@ func Benchmark_RedisDsl_HASH_INCR(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_INCRBY(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1551,8 +1575,8 @@ func Benchmark_RedisDsl_HASH_INCRBY(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_INCRBYFLOAT(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1568,8 +1592,8 @@ func Benchmark_RedisDsl_HASH_INCRBYFLOAT(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_DECR(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1585,8 +1609,8 @@ func Benchmark_RedisDsl_HASH_DECR(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_DECRBY(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1602,8 +1626,8 @@ func Benchmark_RedisDsl_HASH_DECRBY(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_DECRBYFLOAT(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1628,8 +1652,8 @@ func Benchmark_RedisDsl_HASH_DECRBYFLOAT(b *testing.B) {
 //
 
 func Benchmark_RedisDsl_GET(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1645,8 +1669,8 @@ func Benchmark_RedisDsl_GET(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GET_x2(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1663,8 +1687,8 @@ func Benchmark_RedisDsl_GET_x2(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GET_STRING(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1680,8 +1704,8 @@ func Benchmark_RedisDsl_GET_STRING(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GET_INT64(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1697,8 +1721,8 @@ func Benchmark_RedisDsl_GET_INT64(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GET_FLOAT64(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1714,8 +1738,8 @@ func Benchmark_RedisDsl_GET_FLOAT64(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_MGET(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1732,8 +1756,8 @@ func Benchmark_RedisDsl_MGET(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_MGET_STRINGS(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1750,8 +1774,8 @@ func Benchmark_RedisDsl_MGET_STRINGS(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_MGET_INT64S(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1768,8 +1792,8 @@ func Benchmark_RedisDsl_MGET_INT64S(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_MGET_FLOAT64S(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1787,8 +1811,8 @@ func Benchmark_RedisDsl_MGET_FLOAT64S(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GETBIT(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1804,8 +1828,8 @@ func Benchmark_RedisDsl_GETBIT(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GETBIT_x4(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1827,8 +1851,8 @@ func Benchmark_RedisDsl_GETBIT_x4(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GETBITS(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1847,8 +1871,8 @@ func Benchmark_RedisDsl_GETBITS(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_GETBITS_TURNED_ON(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1875,8 +1899,8 @@ func Benchmark_RedisDsl_GETBITS_TURNED_ON(b *testing.B) {
 //
 
 func Benchmark_RedisDsl_HASH_GET(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1893,8 +1917,8 @@ func Benchmark_RedisDsl_HASH_GET(b *testing.B) {
 
 // Compare to Benchmark_RedisDsl_HASHES_GET
 func Benchmark_RedisDsl_HASH_GET_x4(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1916,8 +1940,8 @@ func Benchmark_RedisDsl_HASH_GET_x4(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_GET_STRING(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1932,8 +1956,8 @@ func Benchmark_RedisDsl_HASH_GET_STRING(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_GET_INT64(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1948,8 +1972,8 @@ func Benchmark_RedisDsl_HASH_GET_INT64(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_GET_FLOAT64(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1964,8 +1988,8 @@ func Benchmark_RedisDsl_HASH_GET_FLOAT64(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_MGET(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -1982,8 +2006,8 @@ func Benchmark_RedisDsl_HASH_MGET(b *testing.B) {
 
 // Compare to Benchmark_RedisDsl_HASHES_MGET
 func Benchmark_RedisDsl_HASH_MGET_x4(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2005,8 +2029,8 @@ func Benchmark_RedisDsl_HASH_MGET_x4(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_MGET_STRINGS(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2023,8 +2047,8 @@ func Benchmark_RedisDsl_HASH_MGET_STRINGS(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_MGET_INT64S(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2041,8 +2065,8 @@ func Benchmark_RedisDsl_HASH_MGET_INT64S(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASH_MGET_FLOAT64S(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2068,8 +2092,8 @@ func Benchmark_RedisDsl_HASH_MGET_FLOAT64S(b *testing.B) {
 //
 
 func Benchmark_RedisDsl_HASHES_GET(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2088,8 +2112,8 @@ func Benchmark_RedisDsl_HASHES_GET(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASHES_GET_STRING(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2107,8 +2131,8 @@ func Benchmark_RedisDsl_HASHES_GET_STRING(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASHES_GET_INT64(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2126,8 +2150,8 @@ func Benchmark_RedisDsl_HASHES_GET_INT64(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASHES_GET_FLOAT64(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2145,8 +2169,8 @@ func Benchmark_RedisDsl_HASHES_GET_FLOAT64(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASHES_MGET(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2164,8 +2188,8 @@ func Benchmark_RedisDsl_HASHES_MGET(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASHES_MGET_STRINGS(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2182,8 +2206,8 @@ func Benchmark_RedisDsl_HASHES_MGET_STRINGS(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASHES_MGET_INT64S(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -2200,8 +2224,8 @@ func Benchmark_RedisDsl_HASHES_MGET_INT64S(b *testing.B) {
 }
 
 func Benchmark_RedisDsl_HASHES_MGET_FLOAT64S(b *testing.B) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-	server, err := StartRedisServer(&logger)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+	server, err := StartRedisServer(logger)
 	if nil != err {
 		panic(err)
 	}
@@ -4,39 +4,86 @@
 
 package dog_pool
 
+import "context"
 import "fmt"
+import "crypto/tls"
 import "errors"
+import "sync"
 import "time"
-import "github.com/alecthomas/log4go"
 
 //
 // Redis Connection Pool wrapper
 //
 type RedisConnectionPool struct {
-	Mode    ConnectionMode         "How should we prepare the connection pool?"
-	Size    int                    "(Max) Pool size"
-	Urls    []string               "Redis URLs to connect to"
-	Logger  log4go.Logger          "Logger we are using in the connection pool"
-	Timeout time.Duration          "Timeout to use for connecting to Redis"
-	myPool  *ConnectionPoolWrapper "Connection Pool wrapper"
+	Mode    ConnectionMode                  "How should we prepare the connection pool?"
+	Size    int                             "(Max) Pool size"
+	Urls    []string                        "Redis URLs to connect to"
+	Logger  Logger                          "(optional) Logger we are using in the connection pool, nil logs nothing"
+	Timeout time.Duration                   "Timeout to use for connecting to Redis"
+	myPool  *weightedPool[*RedisConnection] "Connection Pool"
+	myStats *connectionStats                "Counters shared with the connections"
+	mutex   sync.RWMutex                    "Guards myPool, myStats, and the defaults Open sets for String()"
+	opening sync.Mutex                      "Serializes Open, Close and Shutdown"
+
+	DialTimeout  time.Duration "(optional) Timeout for dialing Redis, defaults to Timeout"
+	ReadTimeout  time.Duration "(optional) Timeout for reading each reply, defaults to Timeout"
+	WriteTimeout time.Duration "(optional) Timeout for writing each command, defaults to Timeout"
+
+	HealthCheckInterval time.Duration "(optional) How often to Ping the idle connections, 0 disables the health checks"
+	MaxIdleTime         time.Duration "(optional) Re-open connections idle longer than this, even without the health checks, 0 disables the limit"
+	MaxLifetime         time.Duration "(optional) Re-open connections older than this when they are Pop'd or Push'd, 0 disables the limit"
+	MaxLifetimeJitter   time.Duration "(optional) Re-open connections up to this much before MaxLifetime, defaults to 10% of MaxLifetime"
+
+	MinIdle int "(optional) Number of idle connections to keep open when MaxOpen is set"
+	MaxOpen int "(optional) Open connections on demand up to this many, 0 opens Size connections up front"
+
+	LeakThreshold time.Duration "(optional) Log connections borrowed longer than this with the stack that Pop'd them, 0 disables leak detection"
+
+	WarmUp     WarmUpPolicy "(optional) What to do when AGRESSIVE connections fail to open, defaults to WARMUP_FAIL_FAST"
+	MinHealthy float64      "(optional) Fraction of the AGRESSIVE connections that must open with WARMUP_MIN_HEALTHY"
+	myWarmUp   WarmUpReport "Which connections opened during the last Open"
+
+	Hooks PoolHooks[*RedisConnection] "(optional) Callbacks for the pool's and the connections' lifecycle events"
+
+	BreakerThreshold int           "(optional) Fail fast on a Url after this many consecutive dial/fatal errors, 0 disables the circuit breakers"
+	BreakerCoolDown  time.Duration "(optional) How long to fail fast before probing the Url again, defaults to 10s"
+
+	SentinelUrls []string         "(optional) Sentinels to ask for the master's address, replaces the Urls"
+	MasterName   string           "(optional) Name of the master the Sentinels are monitoring, required with SentinelUrls"
+	mySentinel   *sentinelWatcher "Watches the Sentinels for failovers, nil unless SentinelUrls are set"
+
+	TLSConfig *tls.Config "(optional) TLS configuration for rediss:// urls, setting it dials the other TCP urls with TLS too"
+
+	Retry *RetryPolicy "(optional) Retry policy for the connections, nil disables the retries"
 }
 
 func (p *RedisConnectionPool) String() string {
-	return fmt.Sprintf("RedisConnectionPool { Size=%v, Urls=%v, Timeout=%v }", p.Size, p.Urls, p.Timeout)
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return fmt.Sprintf("RedisConnectionPool { Size=%v, Urls=%v, Timeout=%v }", p.Size, redactRedisUrls(p.Urls), p.Timeout)
+}
+
+//
+// Logger to log to, NopLogger when Logger is nil
+//
+func (p *RedisConnectionPool) logger() leveledLogger {
+	return logTo(p.Logger)
 }
 
 //
 // Is the pool open?
 //
 func (p *RedisConnectionPool) IsOpen() bool {
-	return nil != p.myPool
+	pool, _ := p.pool()
+	return nil != pool && pool.IsOpen()
 }
 
 //
 // Is the pool closed?
 //
 func (p *RedisConnectionPool) IsClosed() bool {
-	return nil == p.myPool
+	return !p.IsOpen()
 }
 
 //
@@ -44,60 +91,224 @@ func (p *RedisConnectionPool) IsClosed() bool {
 // Returns -1 if the pool is not open
 //
 func (p *RedisConnectionPool) Len() int {
-	if p.IsOpen() {
-		return p.myPool.Len()
+	if pool, _ := p.pool(); nil != pool {
+		return pool.Len()
 	}
 	return -1
 }
 
+//
+// Snapshot of the pool's size and counters
+// Returns an empty snapshot if the pool is not open
+//
+func (p *RedisConnectionPool) Stats() PoolStats {
+	pool, stats := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return PoolStats{}
+	}
+	return stats.addTo(pool.Stats())
+}
+
+//
+// Connections that are currently borrowed from the pool, oldest first
+// Returns nil if the pool is not open
+//
+func (p *RedisConnectionPool) Outstanding() []BorrowedObject[*RedisConnection] {
+	if pool, _ := p.pool(); nil != pool {
+		return pool.Outstanding()
+	}
+	return nil
+}
+
+//
+// State of each Url's circuit breaker, keyed by the redacted Urls
+// Returns nil if the pool is not open
+//
+func (p *RedisConnectionPool) CircuitStates() map[string]CircuitState {
+	pool, _ := p.pool()
+	if nil == pool {
+		return nil
+	}
+
+	output := make(map[string]CircuitState)
+	for url, state := range pool.CircuitStates() {
+		output[redactRedisUrl(url)] = state
+	}
+	return output
+}
+
+//
+// Which connections opened during the last Open?
+//
+func (p *RedisConnectionPool) WarmUpReport() WarmUpReport {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.myWarmUp
+}
+
+func (p *RedisConnectionPool) setWarmUpReport(report WarmUpReport) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.myWarmUp = report
+}
+
 //
 // Open the connection pool
 //
+// Re-opening an open pool swaps in the new connections before closing the old ones,
+// connections Pop'd from the old pool are closed when they are Push'd back.
+//
 func (p *RedisConnectionPool) Open() error {
-	p.Close()
+	p.opening.Lock()
+	defer p.opening.Unlock()
 
-	// Default to 15s timeout
+	// Set the defaults under the mutex, the logs in Pop read them with String()
+	p.mutex.Lock()
+
+	// Default to the 15s timeout
 	if time.Duration(0) == p.Timeout {
-		p.Timeout = time.Duration(15) * time.Second
+		p.Timeout = default_timeout
+	}
+
+	// Default to 10s cool-down for the circuit breakers
+	if time.Duration(0) == p.BreakerCoolDown {
+		p.BreakerCoolDown = time.Duration(10) * time.Second
+	}
+	p.mutex.Unlock()
+
+	// Connect to the Urls
+	if 0 == len(p.SentinelUrls) {
+		p.stopSentinel()
+		return p.open(p.Urls)
+	}
+
+	// Or ask the Sentinels for the master, and watch them for failovers
+	if "" == p.MasterName {
+		p.close()
+		return errors.New("[RedisConnectionPool][Open] MasterName is required with SentinelUrls!")
 	}
+	master, err := resolveSentinelMaster(p.SentinelUrls, p.MasterName, p.Timeout, p.Logger)
+	if nil != err {
+		p.close()
+		return err
+	}
+	if err := p.open([]string{master}); nil != err {
+		return err
+	}
+	p.startSentinel(master)
+
+	// Return nil
+	return nil
+}
 
-	// Lambda to iterate the urls
-	nextUrl := loopStrings(p.Urls)
+//
+// Open the connection pool for the urls, swapping it in for the open pool
+//
+func (p *RedisConnectionPool) open(urls []string) error {
+	// Counters shared by the connections
+	stats := &connectionStats{}
+
+	// Check the WarmUp policy
+	if p.MinHealthy < 0 || p.MinHealthy > 1 {
+		p.close()
+		return fmt.Errorf("[RedisConnectionPool][Open] MinHealthy[%v] must be between 0 and 1!", p.MinHealthy)
+	}
+	warm_up := makeWarmUp(p.WarmUp, redactRedisUrl)
 
 	// Lambda for creating the factories
-	var initfn InitFunction
+	var initfn func(nextUrl func() []string, breaker *circuitBreaker) func() (*RedisConnection, error)
 	switch p.Mode {
 	case LAZY:
 		// Create the factory
 		// DON'T Connect to Redis
 		// DON'T Test the connection
-		initfn = func() (interface{}, error) {
-			values := nextUrl()
-			return makeLazyRedisConnection(values[0], values[1], p.Timeout, &p.Logger)
+		initfn = func(nextUrl func() []string, breaker *circuitBreaker) func() (*RedisConnection, error) {
+			return func() (*RedisConnection, error) {
+				values := nextUrl()
+				return makeLazyRedisConnection(values[0], values[1], p.timeouts(), p.TLSConfig, p.Logger, stats, &p.Hooks.ConnectionHooks, breaker)
+			}
 		}
 	case AGRESSIVE:
 		// Create the factory
 		// AND Connect to Redis
 		// AND Test the connection
-		initfn = func() (interface{}, error) {
-			values := nextUrl()
-			return makeAgressiveRedisConnection(values[0], values[1], p.Timeout, &p.Logger)
+		// Failures while warming up are handled by the WarmUp policy
+		initfn = func(nextUrl func() []string, breaker *circuitBreaker) func() (*RedisConnection, error) {
+			return makeWarmUpFactory(warm_up, nextUrl,
+				func(url, id string) (*RedisConnection, error) {
+					return makeAgressiveRedisConnection(url, id, p.timeouts(), p.TLSConfig, p.Logger, stats, &p.Hooks.ConnectionHooks, breaker)
+				},
+				func(url, id string) (*RedisConnection, error) {
+					return makeLazyRedisConnection(url, id, p.timeouts(), p.TLSConfig, p.Logger, stats, &p.Hooks.ConnectionHooks, breaker)
+				})
 		}
 		// No mode specified!
 	default:
+		p.close()
 		return errors.New(fmt.Sprintf("Invalid connection mode: %v", p.Mode))
 	}
 
-	// Create the new pool
-	pool, err := MakeConnectionPoolWrapper(p.Size, initfn)
+	// Create a sub-pool for each url, splitting the Size, MinIdle and MaxOpen by weight
+	pool, err := makeWeightedPool(urls, p.Size, p.MinIdle, p.MaxOpen, func(c *RedisConnection) string { return c.Url }, func(url string) (*Pool[*RedisConnection], *circuitBreaker) {
+		breaker := makeCircuitBreaker(p.BreakerThreshold, p.BreakerCoolDown)
+		factory := initfn(loopStrings([]string{url}), breaker)
+		return &Pool[*RedisConnection]{
+			Factory: func() (*RedisConnection, error) {
+				c, err := factory()
+				if nil == err {
+					c.Retry = p.Retry
+					p.Hooks.created(c)
+				}
+				return c, err
+			},
+			Destroy: func(c *RedisConnection) { c.Close() },
+
+			// Ping the idle connections, re-opening any that were closed
+			HealthCheck:         func(c *RedisConnection) error { return c.Ping() },
+			HealthCheckInterval: p.HealthCheckInterval,
+			MaxIdleTime:         p.MaxIdleTime,
+
+			// Re-open old connections, staggered by the jitter
+			MaxLifetime:       p.MaxLifetime,
+			MaxLifetimeJitter: p.MaxLifetimeJitter,
+
+			// Log the connections that are borrowed too long
+			LeakThreshold: p.LeakThreshold,
+			OnLeak: func(b BorrowedObject[*RedisConnection]) {
+				p.logger().Warn("[RedisConnectionPool][Leak][%s/%s] Connection borrowed since %v by:\n%s", b.Value.logUrl(), b.Value.Id, b.BorrowedAt, b.Stack)
+			},
+		}, breaker
+	})
+	if nil != err {
+		p.close()
+		return err
+	}
 
 	// Error creating the pool?
+	err = pool.Open()
+
+	// Check the connections that opened against the WarmUp policy
+	report, warm_up_err := warm_up.finish(p.MinHealthy)
+	p.setWarmUpReport(report)
+	if report.Failed > 0 {
+		p.logger().Warn("[RedisConnectionPool][Open] Connections failed to open, report=%v", report)
+	}
+	if nil == err && nil != warm_up_err {
+		pool.Close()
+		err = warm_up_err
+	}
+
 	if nil != err {
+		p.close()
 		return err
 	}
 
-	// Save the pointer to the pool
-	p.myPool = pool
+	// Swap in the new pool, and close the previous one
+	if previous := p.swap(pool, stats); nil != previous {
+		previous.Close()
+	}
 
 	// Return nil
 	return nil
@@ -107,50 +318,194 @@ func (p *RedisConnectionPool) Open() error {
 // Close the connection pool
 //
 func (p *RedisConnectionPool) Close() {
-	if p.IsClosed() {
+	p.opening.Lock()
+	defer p.opening.Unlock()
+
+	p.stopSentinel()
+	p.close()
+}
+
+func (p *RedisConnectionPool) close() {
+	// Release the connection pool
+	previous := p.swap(nil, nil)
+	if nil == previous {
 		return
 	}
 
-	// If the pool is not nil,
-	// Then close all the connections and release the pointer
-	if nil != p.myPool {
-		for i := 0; i < p.Size; i++ {
-			// Pop a connection from the pool
-			c, _ := p.Pop()
+	// Close all the idle connections,
+	// Borrowed connections are closed when they are Push'd back
+	previous.Close()
+}
 
-			// Close the connection
-			if nil != c {
-				c.Close()
-			}
-		}
+//
+// Close the connection pool, and wait for the borrowed connections to be Push'd back and closed
+//
+// Returns ErrPoolTimeout if the context's deadline expires first,
+// the remaining connections are closed when they are Push'd back.
+//
+func (p *RedisConnectionPool) Shutdown(ctx context.Context) error {
+	p.opening.Lock()
+	defer p.opening.Unlock()
+
+	p.stopSentinel()
+	pool, _ := p.pool()
+	if nil == pool {
+		return nil
+	}
+
+	// Stop the Pop's, and wait for the Push's
+	err := pool.Shutdown(ctx)
+	if nil != err {
+		p.logger().Warn("[RedisConnectionPool][Shutdown] Connections are still borrowed pool=%v, err=%v", p.String(), err)
 	}
 
 	// Release the connection pool
-	p.myPool = nil
+	p.swap(nil, nil)
+	return err
+}
+
+//
+// Timeouts for the connections, the Dial, Read and Write timeouts fall back to Timeout
+//
+func (p *RedisConnectionPool) timeouts() connectionTimeouts {
+	return connectionTimeouts{Timeout: p.Timeout, Dial: p.DialTimeout, Read: p.ReadTimeout, Write: p.WriteTimeout}
+}
+
+//
+// The open pool and its counters, nil if the pool is not open
+//
+func (p *RedisConnectionPool) pool() (*weightedPool[*RedisConnection], *connectionStats) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.myPool, p.myStats
+}
+
+//
+// Replace the pool and its counters, returning the previous pool
+//
+func (p *RedisConnectionPool) swap(pool *weightedPool[*RedisConnection], stats *connectionStats) *weightedPool[*RedisConnection] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	previous := p.myPool
+	p.myPool, p.myStats = pool, stats
+	return previous
 }
 
 //
 // Get a RedisConnection from the pool
 //
 func (p *RedisConnectionPool) Pop() (*RedisConnection, error) {
+	pool, _ := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return nil, ErrConnectionIsClosed
+	}
+
 	// Pop a connection from the pool
-	c := p.myPool.GetConnection()
+	c, err := pool.Get()
+
+	// Return an error when all connections are exhausted
+	if nil != err {
+		p.logger().Critical("[RedisConnectionPool][Pop] No connections available pool=%v, err=%v", p.String(), err)
+		return nil, err
+	}
 
 	// Return the connection
-	if c != nil {
-		p.Logger.Finest("Removed connection %v", c)
-		return c.(*RedisConnection), nil
+	p.logger().Finest("Removed connection %v", c)
+	p.Hooks.borrowed(c)
+	return c, nil
+}
+
+//
+// Get a RedisConnection from the pool, waiting for one to be returned
+// Returns ErrPoolTimeout if the context's deadline expires first
+//
+func (p *RedisConnectionPool) PopContext(ctx context.Context) (*RedisConnection, error) {
+	pool, _ := p.pool()
+	if nil == pool || pool.IsClosed() {
+		return nil, ErrConnectionIsClosed
 	}
 
-	// Return an error when all connections are exhausted
-	p.Logger.Critical("[RedisConnectionPool][Pop] No connections available pool=%v", p.String())
-	return nil, ErrNoConnectionsAvailable
+	// Wait for a connection from the pool
+	c, err := pool.GetContext(ctx)
+
+	// Return an error when the context is done first
+	if nil != err {
+		p.logger().Warn("[RedisConnectionPool][PopContext] No connections available pool=%v, err=%v", p.String(), err)
+		return nil, err
+	}
+
+	// Return the connection
+	p.logger().Finest("Removed connection %v", c)
+	p.Hooks.borrowed(c)
+	return c, nil
+}
+
+//
+// Get a RedisConnection from the pool, waiting at most timeout for one to be returned
+//
+func (p *RedisConnectionPool) PopTimeout(timeout time.Duration) (*RedisConnection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return p.PopContext(ctx)
 }
 
 //
 // Return a RedisConnection
 //
 func (p *RedisConnectionPool) Push(c *RedisConnection) {
-	p.Logger.Finest("Returned connection %v", c)
-	p.myPool.ReleaseConnection(c)
+	p.Hooks.returned(c)
+	p.logger().Finest("Returned connection %v", c)
+
+	// The pool is closed, or the connection is from before the pool was re-opened,
+	// close the connection instead
+	pool, stats := p.pool()
+	if nil == pool || c.stats != stats {
+		c.Close()
+		return
+	}
+
+	// Closes the connection if the pool is shutting down
+	pool.Release(c)
+}
+
+//
+// Pop a RedisConnection, call fn with it, and Push it back
+//
+// Connections closed by a fatal error, or by a panic in fn, are discarded
+// instead of being returned to the pool. Panics are returned as errors.
+//
+func (p *RedisConnectionPool) Do(fn func(RedisDsl) error) (err error) {
+	c, err := p.Pop()
+	if nil != err {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); nil != r {
+			p.logger().Critical("[RedisConnectionPool][Do] Panic Error = '%v'", r)
+			c.Close()
+			err = panicError(r)
+		}
+		p.pushOrDiscard(c)
+	}()
+
+	return fn(RedisDsl{c})
+}
+
+//
+// Return an open connection to the pool, and discard a closed one
+//
+func (p *RedisConnectionPool) pushOrDiscard(c *RedisConnection) {
+	pool, stats := p.pool()
+	if nil == pool || c.stats != stats || c.IsOpen() {
+		p.Push(c)
+		return
+	}
+
+	p.logger().Finest("Discarded connection %v", c)
+	p.Hooks.returned(c)
+	pool.Discard(c)
 }
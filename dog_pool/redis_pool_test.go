@@ -1,10 +1,13 @@
 package dog_pool
 
+import "context"
+import "strings"
+import "sync"
 import "os/exec"
 import "time"
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
-import "github.com/alecthomas/log4go"
 
 func TestRedisPoolSpecs(t *testing.T) {
 	if testing.Short() {
@@ -18,7 +21,7 @@ func TestRedisPoolSpecs(t *testing.T) {
 
 // Helpers
 func RedisPoolSpecs(c gospec.Context) {
-	var redis_pool_logger = log4go.NewDefaultLogger(log4go.CRITICAL)
+	var redis_pool_logger = MakeStdLogger(log.Default(), LOG_CRITICAL)
 
 	c.Specify("[RedisConnectionPool] New Pool is not open", func() {
 		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 0, Urls: []string{}, Logger: redis_pool_logger}
@@ -80,6 +83,67 @@ func RedisPoolSpecs(c gospec.Context) {
 		c.Expect(connection, gospec.Satisfies, nil == connection)
 	})
 
+	c.Specify("[RedisConnectionPool] PopTimeout from empty pool returns timeout error", func() {
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 0, Urls: []string{}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		// Shouldn't have any errors
+		err := pool.Open()
+		c.Expect(err, gospec.Equals, nil)
+
+		connection, err := pool.PopTimeout(time.Duration(10) * time.Millisecond)
+		c.Expect(err, gospec.Equals, ErrPoolTimeout)
+		c.Expect(connection, gospec.Satisfies, nil == connection)
+	})
+
+	c.Specify("[RedisConnectionPool] PopTimeout from closed pool returns error", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6995"}, Logger: redis_pool_logger}
+
+		connection, err := pool.PopTimeout(time.Duration(10) * time.Millisecond)
+		c.Expect(err, gospec.Equals, ErrConnectionIsClosed)
+		c.Expect(connection, gospec.Satisfies, nil == connection)
+	})
+
+	c.Specify("[RedisConnectionPool] PopTimeout waits for a connection to be Pushed", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6995"}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Borrow the only connection
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 0)
+
+		// Return the connection after a short delay
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			pool.Push(connection)
+		}()
+
+		waited, err := pool.PopTimeout(time.Second)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(waited, gospec.Equals, connection)
+		pool.Push(waited)
+	})
+
+	c.Specify("[RedisConnectionPool] PopContext returns when the context is cancelled", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 0, Urls: []string{}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			cancel()
+		}()
+
+		connection, err := pool.PopContext(ctx)
+		c.Expect(err, gospec.Equals, context.Canceled)
+		c.Expect(connection, gospec.Satisfies, nil == connection)
+	})
+
 	c.Specify("[RedisConnectionPool] Opening connection to Invalid Host/Port has errors", func() {
 		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger}
 		defer pool.Close()
@@ -188,4 +252,559 @@ func RedisPoolSpecs(c gospec.Context) {
 			c.Expect(connection.IsClosed(), gospec.Equals, true)
 		}
 	})
+
+	c.Specify("[RedisConnectionPool] HealthCheck re-opens closed idle connections", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger, HealthCheckInterval: time.Duration(10) * time.Millisecond}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Close the connection and return it to the pool
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		connection.Close()
+		pool.Push(connection)
+
+		// Wait for the health check
+		time.Sleep(time.Duration(50) * time.Millisecond)
+
+		connection, err = pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.IsOpen(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnectionPool] HealthCheck replaces connections idle longer than MaxIdleTime", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger, HealthCheckInterval: time.Duration(10) * time.Millisecond, MaxIdleTime: time.Duration(20) * time.Millisecond}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		pool.Push(connection)
+
+		// Wait for the health check
+		time.Sleep(time.Duration(100) * time.Millisecond)
+
+		replacement, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(replacement, gospec.Satisfies, replacement != connection)
+		c.Expect(replacement.IsOpen(), gospec.Equals, true)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnectionPool] MaxLifetime re-opens old connections when they are Push'd", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger, MaxLifetime: time.Duration(20) * time.Millisecond}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		time.Sleep(time.Duration(30) * time.Millisecond)
+		pool.Push(connection)
+
+		// The old connection was closed, and a new one opened
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+
+		replacement, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(replacement, gospec.Satisfies, replacement != connection)
+		c.Expect(replacement.IsOpen(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnectionPool] Connections get the pool's timeouts", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger, DialTimeout: time.Second, ReadTimeout: time.Minute}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Timeout, gospec.Equals, time.Duration(15)*time.Second)
+		c.Expect(connection.DialTimeout, gospec.Equals, time.Second)
+		c.Expect(connection.ReadTimeout, gospec.Equals, time.Minute)
+		c.Expect(connection.WriteTimeout, gospec.Equals, time.Duration(0))
+
+		// The Write timeout falls back to the Timeout
+		c.Expect(connection.timeouts().write(), gospec.Equals, time.Duration(15)*time.Second)
+		c.Expect(connection.Clone().timeouts(), gospec.Equals, connection.timeouts())
+	})
+
+	c.Specify("[RedisConnectionPool] Retry retries the reads that fail on network errors", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger, Retry: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Retry, gospec.Equals, pool.Retry)
+
+		// Read-only commands are retried
+		reply := connection.Cmd("GET", "bob")
+		c.Expect(reply.Err, gospec.Satisfies, nil != reply.Err)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(3))
+
+		// Writes are never retried
+		reply = connection.Cmd("INCRBY", "bob", 1)
+		c.Expect(reply.Err, gospec.Satisfies, nil != reply.Err)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(4))
+
+		// Nor the pipelined commands
+		connection.Append("GET", "bob")
+		reply = connection.GetReply()
+		c.Expect(reply.Err, gospec.Satisfies, nil != reply.Err)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(5))
+	})
+
+	c.Specify("[RedisConnectionPool] Stats counts the Pops, waits and dial errors", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Stats(), gospec.Equals, PoolStats{})
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Lazy connections dial on their first command
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		reply := connection.Cmd("PING")
+		c.Expect(reply.Err, gospec.Satisfies, nil != reply.Err)
+
+		// The only connection is borrowed
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrNoConnectionsAvailable)
+		_, err = pool.PopTimeout(time.Duration(10) * time.Millisecond)
+		c.Expect(err, gospec.Equals, ErrPoolTimeout)
+
+		stats := pool.Stats()
+		c.Expect(stats.Size, gospec.Equals, 1)
+		c.Expect(stats.Idle, gospec.Equals, 0)
+		c.Expect(stats.InUse, gospec.Equals, 1)
+		c.Expect(stats.PopSuccesses, gospec.Equals, uint64(1))
+		c.Expect(stats.PopFailures, gospec.Equals, uint64(2))
+		c.Expect(stats.Waits, gospec.Equals, uint64(1))
+		c.Expect(stats.WaitTime, gospec.Satisfies, stats.WaitTime >= time.Duration(10)*time.Millisecond)
+		c.Expect(stats.DialErrors, gospec.Equals, uint64(1))
+		c.Expect(stats.FatalErrors, gospec.Equals, uint64(0))
+
+		pool.Push(connection)
+		c.Expect(pool.Stats().Idle, gospec.Equals, 1)
+		c.Expect(pool.Stats().InUse, gospec.Equals, 0)
+	})
+
+	c.Specify("[RedisConnectionPool] Stats counts the connections closed by fatal errors", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		// Stop the server under the open connection
+		server.Close()
+		reply := connection.Cmd("PING")
+		c.Expect(reply.Err, gospec.Satisfies, nil != reply.Err)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+
+		stats := pool.Stats()
+		c.Expect(stats.DialErrors, gospec.Equals, uint64(0))
+		c.Expect(stats.FatalErrors, gospec.Equals, uint64(1))
+	})
+
+	c.Specify("[RedisConnectionPool] MaxOpen opens MinIdle connections, and grows on demand up to MaxOpen", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 500, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger, MinIdle: 1, MaxOpen: 2}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 1)
+
+		connection1, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		connection2, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection2.IsOpen(), gospec.Equals, true)
+
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrNoConnectionsAvailable)
+
+		pool.Push(connection1)
+		pool.Push(connection2)
+		c.Expect(pool.Len(), gospec.Equals, 2)
+	})
+
+	c.Specify("[RedisConnectionPool] Do returns the connection to the pool", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		err = pool.Do(func(dsl RedisDsl) error {
+			c.Expect(pool.Len(), gospec.Equals, 0)
+			return dsl.Cmd("SET", "do-key", "1").Err
+		})
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 1)
+	})
+
+	c.Specify("[RedisConnectionPool] Do discards connections closed by fatal errors", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Stop the server under the open connection
+		server.Close()
+		err = pool.Do(func(dsl RedisDsl) error {
+			return dsl.Cmd("PING").Err
+		})
+		c.Expect(err, gospec.Satisfies, nil != err)
+
+		stats := pool.Stats()
+		c.Expect(stats.Idle, gospec.Equals, 0)
+		c.Expect(stats.InUse, gospec.Equals, 0)
+	})
+
+	c.Specify("[RedisConnectionPool] Do recovers panics and discards the connection", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		err := pool.Do(func(dsl RedisDsl) error {
+			panic("Panic in Do")
+		})
+		c.Expect(err, gospec.Satisfies, nil != err && "Panic in Do" == err.Error())
+
+		stats := pool.Stats()
+		c.Expect(stats.Idle, gospec.Equals, 0)
+		c.Expect(stats.InUse, gospec.Equals, 0)
+	})
+
+	c.Specify("[RedisConnectionPool] Outstanding lists the connections with the stack that Pop'd them", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger, LeakThreshold: time.Hour}
+		defer pool.Close()
+
+		c.Expect(len(pool.Outstanding()), gospec.Equals, 0)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		outstanding := pool.Outstanding()
+		c.Expect(len(outstanding), gospec.Equals, 1)
+		c.Expect(outstanding[0].Value, gospec.Equals, connection)
+		c.Expect(outstanding[0].Stack, gospec.Satisfies, strings.Contains(outstanding[0].Stack, "RedisPoolSpecs"))
+
+		pool.Push(connection)
+		c.Expect(len(pool.Outstanding()), gospec.Equals, 0)
+	})
+
+	c.Specify("[RedisConnectionPool] Shutdown waits for the borrowed connections to be Push'd", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Shutdown(context.Background()), gospec.Equals, nil)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		// Push the connection after a short delay
+		pushed := make(chan bool, 1)
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			pushed <- true
+			pool.Push(connection)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		c.Expect(pool.Shutdown(ctx), gospec.Equals, nil)
+		c.Expect(len(pushed), gospec.Equals, 1)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrConnectionIsClosed)
+	})
+
+	c.Specify("[RedisConnectionPool] Shutdown times out, and late Push's close the connection", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+		defer cancel()
+
+		c.Expect(pool.Shutdown(ctx), gospec.Equals, ErrPoolTimeout)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+		c.Expect(connection.IsOpen(), gospec.Equals, true)
+
+		pool.Push(connection)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnectionPool] Concurrent Open/Close/Pop/Push are safe, run with go test -race", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 4, Urls: []string{server.Connection().Url}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		var wg sync.WaitGroup
+		done := make(chan bool)
+
+		// Pop, use and Push connections until the pool stops changing
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+
+					pool.IsOpen()
+					pool.Len()
+					connection, err := pool.PopTimeout(time.Millisecond)
+					if nil != err {
+						continue
+					}
+					connection.Cmd("PING")
+					pool.Push(connection)
+				}
+			}()
+		}
+
+		// Re-open and close the pool, like a config reload
+		for i := 0; i < 20; i++ {
+			if 0 == i%5 {
+				pool.Close()
+			} else {
+				pool.Open()
+			}
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+		wg.Wait()
+
+		// The pool still works afterwards
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Cmd("PING").Err, gospec.Equals, nil)
+		pool.Push(connection)
+		c.Expect(pool.Len(), gospec.Equals, 4)
+	})
+
+	c.Specify("[RedisConnectionPool] WARMUP_FAIL_FAST reports the URL that failed", func() {
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		err := pool.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+
+		report := pool.WarmUpReport()
+		c.Expect(report.Failed, gospec.Equals, 1)
+		c.Expect(report.Errors["127.0.0.1:6991"], gospec.Equals, err)
+	})
+
+	c.Specify("[RedisConnectionPool] WARMUP_LAZY_FILL opens the pool when some URLs are down", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 4, Urls: []string{server.Connection().Url, "127.0.0.1:6991"}, Logger: redis_pool_logger, WarmUp: WARMUP_LAZY_FILL}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.Len(), gospec.Equals, 4)
+
+		report := pool.WarmUpReport()
+		c.Expect(report.Opened, gospec.Equals, 2)
+		c.Expect(report.Failed, gospec.Equals, 2)
+		c.Expect(report.FailedUrls(), gospec.Satisfies, 1 == len(report.FailedUrls()) && "127.0.0.1:6991" == report.FailedUrls()[0])
+	})
+
+	c.Specify("[RedisConnectionPool] WARMUP_MIN_HEALTHY fails when too few connections open", func() {
+		server, err := StartRedisServer(redis_pool_logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 4, Urls: []string{server.Connection().Url, "127.0.0.1:6991"}, Logger: redis_pool_logger, WarmUp: WARMUP_MIN_HEALTHY, MinHealthy: 0.5}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.WarmUpReport().Healthy(), gospec.Equals, float64(0.5))
+
+		pool.MinHealthy = 0.75
+		c.Expect(pool.Open(), gospec.Equals, ErrWarmUpFailed)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnectionPool] Hooks are called when connections are created, Pop'd and Push'd", func() {
+		created, borrowed, returned := 0, 0, 0
+		pool := RedisConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger}
+		pool.Hooks.OnCreate = func(*RedisConnection) { created++ }
+		pool.Hooks.OnBorrow = func(*RedisConnection) { borrowed++ }
+		pool.Hooks.OnReturn = func(*RedisConnection) { returned++ }
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(created, gospec.Equals, 2)
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(borrowed, gospec.Equals, 1)
+		c.Expect(connection.Hooks, gospec.Equals, &pool.Hooks.ConnectionHooks)
+
+		pool.Push(connection)
+		c.Expect(returned, gospec.Equals, 1)
+	})
+
+	c.Specify("[RedisConnectionPool] Splits the pool between the Urls by weight", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:6991;weight=3", "127.0.0.1:6992"}, Logger: redis_pool_logger}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		counts := map[string]int{}
+		connections := []*RedisConnection{}
+		for i := 0; i < 4; i++ {
+			connection, err := pool.Pop()
+			c.Expect(err, gospec.Equals, nil)
+			counts[connection.Url]++
+			connections = append(connections, connection)
+		}
+		c.Expect(counts, gospec.Equals, map[string]int{"127.0.0.1:6991": 3, "127.0.0.1:6992": 1})
+
+		for _, connection := range connections {
+			pool.Push(connection)
+		}
+		c.Expect(pool.Len(), gospec.Equals, 4)
+	})
+
+	c.Specify("[RedisConnectionPool] Invalid Url weights fail to Open", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:6991;weight=0"}, Logger: redis_pool_logger}
+		defer pool.Close()
+
+		err := pool.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisConnectionPool] Circuit breaker fails fast after BreakerThreshold dial errors", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: redis_pool_logger, BreakerThreshold: 2, BreakerCoolDown: time.Hour}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.CircuitStates(), gospec.Equals, map[string]CircuitState{"127.0.0.1:6991": CIRCUIT_CLOSED})
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+
+		err = connection.Open()
+		c.Expect(err, gospec.Satisfies, nil != err && ErrCircuitOpen != err)
+		err = connection.Open()
+		c.Expect(err, gospec.Satisfies, nil != err && ErrCircuitOpen != err)
+		c.Expect(pool.CircuitStates(), gospec.Equals, map[string]CircuitState{"127.0.0.1:6991": CIRCUIT_OPEN})
+
+		// Fails fast without dialing
+		c.Expect(connection.Open(), gospec.Equals, ErrCircuitOpen)
+		c.Expect(pool.Stats().DialErrors, gospec.Equals, uint64(2))
+
+		// Pop fails fast too
+		pool.Push(connection)
+		_, err = pool.Pop()
+		c.Expect(err, gospec.Equals, ErrCircuitOpen)
+		c.Expect(pool.Stats().PopFailures, gospec.Equals, uint64(1))
+	})
+
+	c.Specify("[RedisConnectionPool] Circuit breaker routes Pop's to the other Urls", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 4, Urls: []string{"127.0.0.1:6991", "127.0.0.1:6992"}, Logger: redis_pool_logger, BreakerThreshold: 1, BreakerCoolDown: time.Hour}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Trip the first url's breaker, the full pool has connections to both urls
+		connections := []*RedisConnection{}
+		for i := 0; i < 4; i++ {
+			connection, err := pool.Pop()
+			c.Expect(err, gospec.Equals, nil)
+			connections = append(connections, connection)
+		}
+		for _, connection := range connections {
+			if "127.0.0.1:6991" == connection.Url {
+				connection.Open()
+			}
+			pool.Push(connection)
+		}
+		c.Expect(pool.CircuitStates()["127.0.0.1:6991"], gospec.Equals, CIRCUIT_OPEN)
+
+		for i := 0; i < 10; i++ {
+			connection, err := pool.Pop()
+			c.Expect(err, gospec.Equals, nil)
+			c.Expect(connection.Url, gospec.Equals, "127.0.0.1:6992")
+			pool.Push(connection)
+		}
+	})
 }
@@ -0,0 +1,63 @@
+//
+// Redis Client with a retry policy written in GO
+//
+
+package dog_pool
+
+import "context"
+import "github.com/RUNDSP/radix/redis"
+
+//
+// Redis Client that retries the read-only Cmd's that fail on network errors
+//
+// Pipelined commands (Append and GetReply) are never retried.
+//
+type retryRedisClient struct {
+	RedisClientInterface
+	policy *RetryPolicy
+}
+
+//
+// Retry the client's read-only Cmd's, replacing the policy it had
+//
+// The context the client is bound to stays outermost, so the retries wait within its deadline.
+//
+func withRedisRetry(policy *RetryPolicy, client RedisClientInterface) RedisClientInterface {
+	if bound, ok := client.(*contextRedisClient); ok {
+		return &contextRedisClient{RedisClientInterface: withRedisRetry(policy, bound.RedisClientInterface), ctx: bound.ctx}
+	}
+	if retried, ok := client.(*retryRedisClient); ok {
+		client = retried.RedisClientInterface
+	}
+	return &retryRedisClient{RedisClientInterface: client, policy: policy}
+}
+
+func (p *retryRedisClient) Cmd(cmd string, args ...interface{}) *redis.Reply {
+	return p.CmdContext(context.Background(), cmd, args...)
+}
+
+func (p *retryRedisClient) CmdContext(ctx context.Context, cmd string, args ...interface{}) *redis.Reply {
+	call := func() *redis.Reply {
+		if client, ok := p.RedisClientInterface.(RedisContextClientInterface); ok {
+			return client.CmdContext(ctx, cmd, args...)
+		}
+		return p.RedisClientInterface.Cmd(cmd, args...)
+	}
+	if !IsReadOnlyCommand(cmd) {
+		return call()
+	}
+
+	var reply *redis.Reply
+	p.policy.do(ctx, func(int) error {
+		reply = call()
+		return reply.Err
+	})
+	return reply
+}
+
+func (p *retryRedisClient) GetReplyContext(ctx context.Context) *redis.Reply {
+	if client, ok := p.RedisClientInterface.(RedisContextClientInterface); ok {
+		return client.GetReplyContext(ctx)
+	}
+	return p.RedisClientInterface.GetReply()
+}
@@ -0,0 +1,269 @@
+//
+// Redis Sentinel master discovery written in GO
+//
+
+package dog_pool
+
+import "fmt"
+import "net"
+import "strings"
+import "sync"
+import "time"
+import "github.com/RUNDSP/radix/extra/pubsub"
+import "github.com/RUNDSP/radix/redis"
+
+//
+// Channel the Sentinels announce the failovers on
+//
+const sentinel_switch_master = "+switch-master"
+
+//
+// How long to wait before asking the Sentinels again when none of them answered
+//
+const sentinel_retry_interval = time.Duration(1) * time.Second
+
+//
+// Subscribes to +switch-master on one Sentinel at a time, and re-opens the pool on the new master
+//
+type sentinelWatcher struct {
+	pool *RedisConnectionPool
+	urls []string "Sentinels to subscribe to, in order"
+	name string   "Name of the master"
+
+	mutex   sync.Mutex    "Guards master, client and stopped"
+	master  string        "Address of the master the pool is connected to"
+	client  *redis.Client "Connection subscribed to the Sentinel, closed to stop the watcher"
+	stopped bool
+	stop    chan struct{}
+}
+
+//
+// Address of the master the Sentinels resolved, "" unless SentinelUrls are set
+//
+func (p *RedisConnectionPool) MasterUrl() string {
+	if w := p.sentinel(); nil != w {
+		return w.Master()
+	}
+	return ""
+}
+
+func (p *RedisConnectionPool) sentinel() *sentinelWatcher {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.mySentinel
+}
+
+//
+// Start watching the Sentinels, replacing the previous watcher
+//
+func (p *RedisConnectionPool) startSentinel(master string) {
+	p.stopSentinel()
+
+	w := &sentinelWatcher{pool: p, urls: p.SentinelUrls, name: p.MasterName, master: master, stop: make(chan struct{})}
+	p.mutex.Lock()
+	p.mySentinel = w
+	p.mutex.Unlock()
+
+	go w.run()
+}
+
+//
+// Stop watching the Sentinels, without waiting for the watcher to exit
+//
+func (p *RedisConnectionPool) stopSentinel() {
+	p.mutex.Lock()
+	w := p.mySentinel
+	p.mySentinel = nil
+	p.mutex.Unlock()
+
+	if nil != w {
+		w.close()
+	}
+}
+
+//
+// The master moved, re-open the pool on the new master
+//
+// Connections Pop'd from the old master are closed when they are Push'd back.
+//
+func (p *RedisConnectionPool) failover(w *sentinelWatcher, master string) {
+	p.opening.Lock()
+	defer p.opening.Unlock()
+
+	// Closed or re-opened while the watcher was waiting?
+	if w != p.sentinel() || master == w.Master() {
+		return
+	}
+
+	p.logger().Warn("[RedisConnectionPool][Failover][%s] Master moved from %s to %s", w.name, w.Master(), master)
+	if err := p.open([]string{master}); nil != err {
+		p.logger().Critical("[RedisConnectionPool][Failover][%s] Failed to open the new master %s, Error = %v", w.name, master, err)
+		return
+	}
+	w.setMaster(master)
+}
+
+//
+// Ask each of the Sentinels for the master's address, until one of them knows it
+//
+func resolveSentinelMaster(urls []string, name string, timeout time.Duration, logger Logger) (string, error) {
+	var err error
+	for _, url := range urls {
+		c := &RedisConnection{Url: url, Id: "Sentinel", Logger: logger, Timeout: timeout}
+		reply := c.Cmd("SENTINEL", "get-master-addr-by-name", name)
+		c.Close()
+
+		var values []string
+		switch {
+		case nil != reply.Err:
+			err = reply.Err
+		case redis.NilReply == reply.Type:
+			err = fmt.Errorf("Unknown master[%s]", name)
+		default:
+			if values, err = reply.List(); nil == err && 2 != len(values) {
+				err = fmt.Errorf("Expected [host, port] from get-master-addr-by-name, Actual=%v", values)
+			}
+		}
+		if nil == err {
+			return net.JoinHostPort(values[0], values[1]), nil
+		}
+
+		logTo(logger).Warn("[RedisConnectionPool][Sentinel][%s] Failed to resolve the master[%s], Error = %v", redactRedisUrl(url), name, err)
+	}
+	return "", fmt.Errorf("[RedisConnectionPool][Open] No Sentinel resolved the master[%s], SentinelUrls=%v, Error = %v", name, redactRedisUrls(urls), err)
+}
+
+//
+// Parse the new master from a +switch-master message: "<name> <old host> <old port> <new host> <new port>"
+//
+func parseSwitchMaster(name, message string) (string, bool) {
+	fields := strings.Fields(message)
+	if 5 != len(fields) || name != fields[0] {
+		return "", false
+	}
+	return net.JoinHostPort(fields[3], fields[4]), true
+}
+
+//
+//  ========================================
+//
+// sentinelWatcher Utils:
+//
+//  ========================================
+//
+
+func (w *sentinelWatcher) Master() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.master
+}
+
+func (w *sentinelWatcher) setMaster(master string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.master = master
+}
+
+func (w *sentinelWatcher) isStopped() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.stopped
+}
+
+//
+// Remember the subscribed connection, returns false if the watcher was stopped
+//
+func (w *sentinelWatcher) setClient(client *redis.Client) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.client = client
+	return !w.stopped
+}
+
+//
+// Stop the watcher, closing the subscribed connection to interrupt it
+//
+func (w *sentinelWatcher) close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stopped {
+		return
+	}
+	w.stopped = true
+	close(w.stop)
+	if nil != w.client {
+		w.client.Close()
+	}
+}
+
+//
+// Watch the Sentinels in turn, until the watcher is stopped
+//
+func (w *sentinelWatcher) run() {
+	for !w.isStopped() {
+		for _, url := range w.urls {
+			if w.isStopped() {
+				return
+			}
+			if err := w.watch(url); nil != err {
+				w.pool.logger().Warn("[RedisConnectionPool][Sentinel][%s] Error = %v", redactRedisUrl(url), err)
+			}
+		}
+
+		// None of the Sentinels answered, wait before trying them again
+		select {
+		case <-w.stop:
+			return
+		case <-time.After(sentinel_retry_interval):
+		}
+	}
+}
+
+//
+// Subscribe to +switch-master on the Sentinel, and follow the failovers until the connection fails
+//
+func (w *sentinelWatcher) watch(url string) error {
+	c := &RedisConnection{Url: url, Id: "Sentinel", Logger: w.pool.Logger, Timeout: w.pool.Timeout}
+	defer c.Close()
+
+	client, err := c.Client()
+	if nil != err {
+		return err
+	}
+	if !w.setClient(client) {
+		return nil
+	}
+	defer w.setClient(nil)
+
+	sub := pubsub.NewSubClient(client)
+	if reply := sub.Subscribe(sentinel_switch_master); nil != reply.Err {
+		return reply.Err
+	}
+
+	// Catch up on the failovers we missed while we weren't subscribed
+	if master, err := resolveSentinelMaster([]string{url}, w.name, w.pool.Timeout, w.pool.Logger); nil == err {
+		w.pool.failover(w, master)
+	}
+
+	for {
+		reply := sub.Receive()
+		switch {
+		case w.isStopped():
+			return nil
+		case nil != reply.Err && isTimeout(reply.Err):
+			continue
+		case nil != reply.Err:
+			return reply.Err
+		case pubsub.MessageReply == reply.Type:
+			if master, ok := parseSwitchMaster(w.name, reply.Message); ok {
+				w.pool.failover(w, master)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package dog_pool
+
+import "fmt"
+import "os"
+import "path/filepath"
+
+//
+// Redis Sentinel process monitoring a master
+//
+type RedisSentinelProcess struct {
+	*RedisServerProcess
+	dir string "Directory for the Sentinel's config file"
+}
+
+//
+// Start a redis-server in Sentinel mode, monitoring the master under the name
+//
+// Failures are detected after 1s, so failovers are quick enough for the tests.
+//
+func StartRedisSentinel(logger Logger, name string, master *RedisServerProcess) (*RedisSentinelProcess, error) {
+	dir, err := os.MkdirTemp("", "dog_pool_sentinel")
+	if nil != err {
+		return nil, err
+	}
+
+	// Sentinels rewrite their config file, so it has to be a file of its own
+	server, err := startRedisServer(logger, func(port int) ([]string, error) {
+		config := filepath.Join(dir, "sentinel.conf")
+		lines := fmt.Sprintf("port %d\ndir %s\nsentinel monitor %s 127.0.0.1 %d 1\nsentinel down-after-milliseconds %s 1000\nsentinel failover-timeout %s 2000\n",
+			port, dir, name, master.port, name, name)
+		if err := os.WriteFile(config, []byte(lines), 0600); nil != err {
+			return nil, err
+		}
+		return []string{config, "--sentinel"}, nil
+	})
+	if nil != err {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &RedisSentinelProcess{RedisServerProcess: server, dir: dir}, nil
+}
+
+//
+// Close the Sentinel and remove its config file
+//
+func (p *RedisSentinelProcess) Close() error {
+	p.RedisServerProcess.Close()
+
+	if "" != p.dir {
+		os.RemoveAll(p.dir)
+	}
+	p.dir = ""
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package dog_pool
+
+import "testing"
+import "time"
+import "log"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestRedisSentinelSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(RedisSentinelSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func RedisSentinelSpecs(c gospec.Context) {
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+
+	c.Specify("[RedisSentinel] Parses the new master from +switch-master", func() {
+		master, ok := parseSwitchMaster("mymaster", "mymaster 127.0.0.1 6379 127.0.0.1 6380")
+		c.Expect(ok, gospec.Equals, true)
+		c.Expect(master, gospec.Equals, "127.0.0.1:6380")
+
+		_, ok = parseSwitchMaster("mymaster", "othermaster 127.0.0.1 6379 127.0.0.1 6380")
+		c.Expect(ok, gospec.Equals, false)
+
+		_, ok = parseSwitchMaster("mymaster", "mymaster 127.0.0.1 6379")
+		c.Expect(ok, gospec.Equals, false)
+	})
+
+	c.Specify("[RedisSentinel] Pools without SentinelUrls have no MasterUrl", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, Urls: []string{"127.0.0.1:6991"}, Logger: logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.MasterUrl(), gospec.Equals, "")
+	})
+
+	c.Specify("[RedisSentinel] MasterName is required with SentinelUrls", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, SentinelUrls: []string{"127.0.0.1:6991"}, Logger: logger}
+		defer pool.Close()
+
+		err := pool.Open()
+		c.Expect(err.Error(), gospec.Equals, "[RedisConnectionPool][Open] MasterName is required with SentinelUrls!")
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+	})
+
+	c.Specify("[RedisSentinel] Fails to Open when no Sentinel answers", func() {
+		pool := RedisConnectionPool{Mode: LAZY, Size: 1, SentinelUrls: []string{"127.0.0.1:6991", "127.0.0.1:6992"}, MasterName: "mymaster", Logger: logger}
+		defer pool.Close()
+
+		err := pool.Open()
+		c.Expect(err, gospec.Satisfies, nil != err)
+		c.Expect(pool.IsClosed(), gospec.Equals, true)
+		c.Expect(pool.MasterUrl(), gospec.Equals, "")
+	})
+
+	c.Specify("[RedisSentinel] Connects to the master the Sentinels resolve", func() {
+		master, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer master.Close()
+
+		sentinel, err := StartRedisSentinel(logger, "mymaster", master)
+		if nil != err {
+			panic(err)
+		}
+		defer sentinel.Close()
+
+		pool := RedisConnectionPool{Mode: AGRESSIVE, Size: 2, SentinelUrls: []string{"127.0.0.1:6991", sentinel.Url()}, MasterName: "mymaster", Logger: logger}
+		defer pool.Close()
+
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		c.Expect(pool.MasterUrl(), gospec.Equals, master.Url())
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Url, gospec.Equals, master.Url())
+		pool.Push(connection)
+	})
+
+	c.Specify("[RedisSentinel] Re-dials the new master after a failover", func() {
+		master, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer master.Close()
+
+		replica, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer replica.Close()
+		c.Expect(replica.Connection().Cmd("REPLICAOF", "127.0.0.1", master.port).Err, gospec.Equals, nil)
+
+		sentinel, err := StartRedisSentinel(logger, "mymaster", master)
+		if nil != err {
+			panic(err)
+		}
+		defer sentinel.Close()
+
+		pool := RedisConnectionPool{Mode: LAZY, Size: 2, SentinelUrls: []string{sentinel.Url()}, MasterName: "mymaster", Logger: logger}
+		defer pool.Close()
+		c.Expect(pool.Open(), gospec.Equals, nil)
+
+		// Borrowed before the failover
+		old, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(old.Url, gospec.Equals, master.Url())
+
+		// Retry until the Sentinel has found the replica
+		deadline := time.Now().Add(time.Duration(15) * time.Second)
+		for time.Now().Before(deadline) && nil != sentinel.Connection().Cmd("SENTINEL", "FAILOVER", "mymaster").Err {
+			time.Sleep(time.Duration(500) * time.Millisecond)
+		}
+		for time.Now().Before(deadline) && replica.Url() != pool.MasterUrl() {
+			time.Sleep(time.Duration(100) * time.Millisecond)
+		}
+		c.Expect(pool.MasterUrl(), gospec.Equals, replica.Url())
+
+		connection, err := pool.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(connection.Url, gospec.Equals, replica.Url())
+		pool.Push(connection)
+
+		// Drained when it comes back
+		pool.Push(old)
+		c.Expect(old.IsClosed(), gospec.Equals, true)
+	})
+}
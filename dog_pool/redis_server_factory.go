@@ -2,21 +2,28 @@ package dog_pool
 
 import "fmt"
 import "os/exec"
-import "errors"
 import "time"
-import "github.com/alecthomas/log4go"
 
 type RedisServerProcess struct {
 	port       int
-	logger     *log4go.Logger
+	logger     Logger
 	connection *RedisConnection
 	cmd        *exec.Cmd
 }
 
-func StartRedisServer(logger *log4go.Logger) (*RedisServerProcess, error) {
+func StartRedisServer(logger Logger) (*RedisServerProcess, error) {
+	return startRedisServer(logger, func(port int) ([]string, error) {
+		return []string{"--port", fmt.Sprintf("%d", port)}, nil
+	})
+}
+
+//
+// Start a redis-server, with the arguments for the port it listens on
+//
+func startRedisServer(logger Logger, args func(port int) ([]string, error)) (*RedisServerProcess, error) {
 	var err error
 	if nil == logger {
-		return nil, errors.New("Nil logger")
+		logger = NopLogger{}
 	}
 
 	server := &RedisServerProcess{}
@@ -27,7 +34,11 @@ func StartRedisServer(logger *log4go.Logger) (*RedisServerProcess, error) {
 	}
 
 	// Start the server ...
-	server.cmd = exec.Command("redis-server", "--port", fmt.Sprintf("%d", server.port))
+	server_args, err := args(server.port)
+	if nil != err {
+		return nil, err
+	}
+	server.cmd = exec.Command("redis-server", server_args...)
 	err = server.cmd.Start()
 	if nil != err {
 		return nil, err
@@ -39,6 +50,13 @@ func StartRedisServer(logger *log4go.Logger) (*RedisServerProcess, error) {
 	return server, nil
 }
 
+//
+// Url of the redis-server
+//
+func (p *RedisServerProcess) Url() string {
+	return fmt.Sprintf("127.0.0.1:%d", p.port)
+}
+
 //
 // Close the redis-server and redis-connection
 //
@@ -69,7 +87,7 @@ func (p *RedisServerProcess) Connection() *RedisConnection {
 
 	if nil == p.connection {
 		p.connection = &RedisConnection{
-			Url:    fmt.Sprintf("127.0.0.1:%d", p.port),
+			Url:    p.Url(),
 			Logger: p.logger,
 			Id:     "Test",
 		}
@@ -1,8 +1,8 @@
 package dog_pool
 
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
-import "github.com/alecthomas/log4go"
 
 func TestRedisServerProcessSpecs(t *testing.T) {
 	if testing.Short() {
@@ -15,22 +15,22 @@ func TestRedisServerProcessSpecs(t *testing.T) {
 }
 
 func RedisServerProcessSpecs(c gospec.Context) {
-	logger := log4go.NewDefaultLogger(log4go.CRITICAL)
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
 
 	c.Specify("[RedisServerProcess] Starts a new Redis-Server", func() {
-		server, err := StartRedisServer(&logger)
+		server, err := StartRedisServer(logger)
 		defer server.Close()
 
 		c.Expect(err, gospec.Equals, nil)
 		c.Expect(server, gospec.Satisfies, server != nil)
-		c.Expect(server.logger, gospec.Equals, &logger)
+		c.Expect(server.logger, gospec.Equals, logger)
 		c.Expect(server.port, gospec.Satisfies, server.port >= 1024)
 		c.Expect(server.cmd, gospec.Satisfies, nil != server.cmd)
 		c.Expect(server.connection, gospec.Satisfies, nil == server.connection)
 	})
 
 	c.Specify("[RedisServerProcess] Creates a connection to a Redis-Server", func() {
-		server, err := StartRedisServer(&logger)
+		server, err := StartRedisServer(logger)
 		defer server.Close()
 
 		c.Expect(err, gospec.Equals, nil)
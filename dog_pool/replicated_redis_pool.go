@@ -0,0 +1,236 @@
+//
+// Replicated Redis Connection Pool written in GO
+//
+
+package dog_pool
+
+import "errors"
+import "fmt"
+import "math/rand"
+import "github.com/RUNDSP/radix/redis"
+
+//
+// Redis master and its replicas, the reads go to the replicas and the writes go to the master
+//
+// Commands are classified with IsReadOnlyCommand.
+// Replicas lag behind the master, use DoMaster or ForceMaster to read your own writes.
+//
+type ReplicatedRedisPool struct {
+	Master   *RedisConnectionPool   "Open pool for the master, gets the writes"
+	Replicas []*RedisConnectionPool "(optional) Open pools for the replicas, get the reads"
+}
+
+//
+// Redis Client that sends the reads to one of the replicas, and the writes to the master
+//
+// Reads fall back to the master when the replica's pool has no connections available.
+// Connections are Pop'd while commands are pipelined, and Push'd back when the pipeline is empty.
+// Like RedisConnection, a ReplicatedRedisClient is not safe for concurrent use.
+//
+type ReplicatedRedisClient struct {
+	Pool        *ReplicatedRedisPool "Pools the connections are borrowed from"
+	ForceMaster bool                 "Send the reads to the master too, for read-your-writes sections"
+
+	master      *RedisConnection     "Connection borrowed from the master, may be nil"
+	replica     *RedisConnection     "Connection borrowed from a replica, may be nil"
+	replicaPool *RedisConnectionPool "Pool the replica's connection was borrowed from"
+	queue       []*replicatedCommand "Pipelined commands waiting for their replies"
+}
+
+//
+// Pipelined command, and the connection it was sent to
+//
+type replicatedCommand struct {
+	connection *RedisConnection
+	err        error "Error borrowing a connection for the command"
+}
+
+//
+// Make a pool for the master and the replicas
+//
+func MakeReplicatedRedisPool(master *RedisConnectionPool, replicas ...*RedisConnectionPool) (*ReplicatedRedisPool, error) {
+	if nil == master {
+		return nil, errors.New("[ReplicatedRedisPool][Make] Master must not be nil!")
+	}
+	return &ReplicatedRedisPool{Master: master, Replicas: replicas}, nil
+}
+
+func (p *ReplicatedRedisPool) String() string {
+	return fmt.Sprintf("ReplicatedRedisPool { Master=%v, Replicas=%v }", p.Master, len(p.Replicas))
+}
+
+//
+// Make a client that sends the reads to the replicas
+//
+func (p *ReplicatedRedisPool) Client() *ReplicatedRedisClient {
+	return &ReplicatedRedisClient{Pool: p}
+}
+
+//
+// Call fn with a client that sends the reads to the replicas, and Push the connections back
+// Panics are returned as errors
+//
+func (p *ReplicatedRedisPool) Do(fn func(RedisDsl) error) error {
+	return p.do(false, fn)
+}
+
+//
+// Call fn with a client that sends every command to the master, to read your own writes
+// Panics are returned as errors
+//
+func (p *ReplicatedRedisPool) DoMaster(fn func(RedisDsl) error) error {
+	return p.do(true, fn)
+}
+
+func (p *ReplicatedRedisPool) do(force_master bool, fn func(RedisDsl) error) (err error) {
+	client := p.Client()
+	client.ForceMaster = force_master
+
+	defer func() {
+		if r := recover(); nil != r {
+			p.Master.logger().Critical("[ReplicatedRedisPool][Do] Panic Error = '%v'", r)
+			err = panicError(r)
+		}
+		client.Close()
+	}()
+
+	return fn(RedisDsl{client})
+}
+
+//
+//  ========================================
+//
+// RedisClientInterface implementation:
+//
+//  ========================================
+//
+
+//
+// Close discards the pipelined commands, and Push'es the borrowed connections back to their pools.
+// Connections with unread replies are closed first.
+//
+func (p *ReplicatedRedisClient) Close() error {
+	if 0 != len(p.queue) {
+		for _, c := range []*RedisConnection{p.master, p.replica} {
+			if nil != c {
+				c.Close()
+			}
+		}
+	}
+
+	p.queue = nil
+	p.release()
+	return nil
+}
+
+//
+// Cmd calls the given Redis command on a replica or the master:
+// - Calls Append(...)
+// - Returns GetReply()
+//
+func (p *ReplicatedRedisClient) Cmd(cmd string, args ...interface{}) *redis.Reply {
+	p.Append(cmd, args...)
+	return p.GetReply()
+}
+
+//
+// Append adds the given call to the pipeline queue of a replica, or the master.
+// Use GetReply() to read the reply.
+//
+func (p *ReplicatedRedisClient) Append(cmd string, args ...interface{}) {
+	command := &replicatedCommand{}
+	p.queue = append(p.queue, command)
+
+	if !p.ForceMaster && IsReadOnlyCommand(cmd) {
+		command.connection = p.replicaConnection()
+	}
+	if nil == command.connection {
+		command.connection, command.err = p.masterConnection()
+	}
+	if nil != command.err {
+		return
+	}
+
+	command.connection.Append(cmd, args...)
+}
+
+//
+// GetReply returns the reply for the next request in the pipeline queue.
+// Error reply with PipelineQueueEmptyError is returned,
+// if the pipeline queue is empty.
+//
+func (p *ReplicatedRedisClient) GetReply() *redis.Reply {
+	if 0 == len(p.queue) {
+		return &redis.Reply{Type: redis.ErrorReply, Err: redis.PipelineQueueEmptyError}
+	}
+
+	command := p.queue[0]
+	p.queue = p.queue[1:]
+
+	var reply *redis.Reply
+	if nil != command.err {
+		reply = &redis.Reply{Type: redis.ErrorReply, Err: command.err}
+	} else {
+		reply = command.connection.GetReply()
+	}
+
+	// Return the connections once the pipeline is empty
+	if 0 == len(p.queue) {
+		p.release()
+	}
+
+	return reply
+}
+
+//
+//  ========================================
+//
+// ReplicatedRedisClient Utils:
+//
+//  ========================================
+//
+
+//
+// Connection to the master, borrowing one if necessary
+//
+func (p *ReplicatedRedisClient) masterConnection() (*RedisConnection, error) {
+	if nil == p.master {
+		c, err := p.Pool.Master.Pop()
+		if nil != err {
+			return nil, err
+		}
+		p.master = c
+	}
+	return p.master, nil
+}
+
+//
+// Connection to a random replica, borrowing one if necessary
+// Returns nil if there are no replicas, or the replica has no connections available
+//
+func (p *ReplicatedRedisClient) replicaConnection() *RedisConnection {
+	if nil == p.replica && 0 != len(p.Pool.Replicas) {
+		pool := p.Pool.Replicas[rand.Intn(len(p.Pool.Replicas))]
+		c, err := pool.Pop()
+		if nil != err {
+			pool.logger().Warn("[ReplicatedRedisClient][Append] Reading from the master, the replica has no connections available pool=%v, err=%v", pool, err)
+			return nil
+		}
+		p.replica, p.replicaPool = c, pool
+	}
+	return p.replica
+}
+
+//
+// Push the borrowed connections back to their pools
+//
+func (p *ReplicatedRedisClient) release() {
+	if nil != p.master {
+		p.Pool.Master.Push(p.master)
+		p.master = nil
+	}
+	if nil != p.replica {
+		p.replicaPool.Push(p.replica)
+		p.replica, p.replicaPool = nil, nil
+	}
+}
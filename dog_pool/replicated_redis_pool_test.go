@@ -0,0 +1,187 @@
+package dog_pool
+
+import "errors"
+import "fmt"
+import "testing"
+import "time"
+import "log"
+import "github.com/RUNDSP/radix/redis"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestReplicatedRedisPoolSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(ReplicatedRedisPoolSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func ReplicatedRedisPoolSpecs(c gospec.Context) {
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+
+	// LAZY pools for invalid urls, the commands fail but the routing still works
+	makePool := func(url string, size int) *RedisConnectionPool {
+		pool := &RedisConnectionPool{Mode: LAZY, Size: size, Urls: []string{url}, Logger: logger}
+		if err := pool.Open(); nil != err {
+			panic(err)
+		}
+		return pool
+	}
+	urlOf := func(client *ReplicatedRedisClient, i int) string {
+		return client.queue[i].connection.Url
+	}
+
+	c.Specify("[ReplicatedRedisPool] Requires a master", func() {
+		pool, err := MakeReplicatedRedisPool(nil)
+		c.Expect(err.Error(), gospec.Equals, "[ReplicatedRedisPool][Make] Master must not be nil!")
+		c.Expect(pool, gospec.Satisfies, nil == pool)
+	})
+
+	c.Specify("[ReplicatedRedisPool] Reads go to the replica, and writes to the master", func() {
+		master := makePool("127.0.0.1:6991", 1)
+		defer master.Close()
+		replica := makePool("127.0.0.1:6992", 1)
+		defer replica.Close()
+
+		pool, _ := MakeReplicatedRedisPool(master, replica)
+		client := pool.Client()
+		defer client.Close()
+
+		client.Append("MGET", "a", "b")
+		client.Append("SET", "a", "1")
+		client.Append("hgetall", "c")
+		c.Expect(urlOf(client, 0), gospec.Equals, "127.0.0.1:6992")
+		c.Expect(urlOf(client, 1), gospec.Equals, "127.0.0.1:6991")
+		c.Expect(urlOf(client, 2), gospec.Equals, "127.0.0.1:6992")
+
+		// Connections are returned once the pipeline is empty
+		c.Expect(master.Len(), gospec.Equals, 0)
+		for i := 0; i < 3; i++ {
+			c.Expect(client.GetReply().Err, gospec.Equals, ErrConnectionIsClosed)
+		}
+		c.Expect(master.Len(), gospec.Equals, 1)
+		c.Expect(replica.Len(), gospec.Equals, 1)
+	})
+
+	c.Specify("[ReplicatedRedisPool] ForceMaster sends the reads to the master", func() {
+		master := makePool("127.0.0.1:6991", 1)
+		defer master.Close()
+		replica := makePool("127.0.0.1:6992", 1)
+		defer replica.Close()
+
+		pool, _ := MakeReplicatedRedisPool(master, replica)
+		client := pool.Client()
+		client.ForceMaster = true
+		defer client.Close()
+
+		client.Append("GET", "a")
+		c.Expect(urlOf(client, 0), gospec.Equals, "127.0.0.1:6991")
+	})
+
+	c.Specify("[ReplicatedRedisPool] Reads fall back to the master without a replica", func() {
+		master := makePool("127.0.0.1:6991", 1)
+		defer master.Close()
+		replica := makePool("127.0.0.1:6992", 0)
+		defer replica.Close()
+
+		pool, _ := MakeReplicatedRedisPool(master)
+		client := pool.Client()
+		defer client.Close()
+		client.Append("GET", "a")
+		c.Expect(urlOf(client, 0), gospec.Equals, "127.0.0.1:6991")
+
+		// The replica has no connections available
+		pool, _ = MakeReplicatedRedisPool(master, replica)
+		other := pool.Client()
+		defer other.Close()
+		other.Append("GET", "a")
+		c.Expect(other.queue[0].err, gospec.Equals, ErrNoConnectionsAvailable)
+	})
+
+	c.Specify("[ReplicatedRedisPool] Close closes the connections with unread replies", func() {
+		master := makePool("127.0.0.1:6991", 1)
+		defer master.Close()
+
+		pool, _ := MakeReplicatedRedisPool(master)
+		client := pool.Client()
+		client.Append("SET", "a", "1")
+		connection := client.master
+
+		c.Expect(client.Close(), gospec.Equals, nil)
+		c.Expect(connection.IsClosed(), gospec.Equals, true)
+		c.Expect(master.Len(), gospec.Equals, 1)
+		c.Expect(client.GetReply().Err, gospec.Equals, redis.PipelineQueueEmptyError)
+	})
+
+	c.Specify("[ReplicatedRedisPool] Do recovers panics and returns the connections", func() {
+		master := makePool("127.0.0.1:6991", 1)
+		defer master.Close()
+
+		pool, _ := MakeReplicatedRedisPool(master)
+		err := pool.Do(func(dsl RedisDsl) error {
+			dsl.Append("SET", "a", "1")
+			panic(errors.New("bob"))
+		})
+		c.Expect(err.Error(), gospec.Equals, "bob")
+		c.Expect(master.Len(), gospec.Equals, 1)
+	})
+
+	c.Specify("[ReplicatedRedisPool] Reads the master's writes from the replica", func() {
+		master_server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer master_server.Close()
+
+		replica_server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer replica_server.Close()
+		c.Expect(replica_server.Connection().Cmd("REPLICAOF", "127.0.0.1", master_server.port).Err, gospec.Equals, nil)
+
+		master := &RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{master_server.Url()}, Logger: logger}
+		c.Expect(master.Open(), gospec.Equals, nil)
+		defer master.Close()
+		replica := &RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{replica_server.Url()}, Logger: logger}
+		c.Expect(replica.Open(), gospec.Equals, nil)
+		defer replica.Close()
+
+		pool, _ := MakeReplicatedRedisPool(master, replica)
+
+		// Read your own writes from the master
+		err = pool.DoMaster(func(dsl RedisDsl) error {
+			for i := 0; i < 3; i++ {
+				if reply := dsl.Cmd("SET", fmt.Sprintf("key:%d", i), fmt.Sprintf("value:%d", i)); nil != reply.Err {
+					return reply.Err
+				}
+			}
+			values, err := dsl.MGET_STRINGS("key:0", "key:1", "key:2")
+			c.Expect(len(values), gospec.Equals, 3)
+			return err
+		})
+		c.Expect(err, gospec.Equals, nil)
+
+		// Replication is asynchronous
+		deadline := time.Now().Add(time.Duration(5) * time.Second)
+		var values []*string
+		for time.Now().Before(deadline) {
+			err = pool.Do(func(dsl RedisDsl) error {
+				values, err = dsl.MGET_STRINGS("key:0", "key:1", "key:2")
+				return err
+			})
+			if nil == err && nil != values[2] {
+				break
+			}
+			time.Sleep(time.Duration(100) * time.Millisecond)
+		}
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(*values[2], gospec.Equals, "value:2")
+
+		// Writes go to the master, the replica would fail them with READONLY
+		err = pool.Do(func(dsl RedisDsl) error { return dsl.Cmd("SET", "key:3", "value:3").Err })
+		c.Expect(err, gospec.Equals, nil)
+	})
+}
@@ -0,0 +1,94 @@
+//
+// Retry Policy written in GO
+//
+
+package dog_pool
+
+import "context"
+import "errors"
+import "io"
+import "math/rand"
+import "net"
+import "time"
+
+//
+// Retry the commands that fail on network errors, with exponential backoff
+//
+// Only the commands that are safe to send twice are retried:
+// the read-only Redis commands (see IsReadOnlyCommand) and the Memcached Get/GetMulti.
+// INCRBY and the other writes may have been applied before the error, so they are never retried.
+//
+type RetryPolicy struct {
+	MaxAttempts int           "Attempts per command, including the first one, 1 or less disables the retries"
+	BaseDelay   time.Duration "(optional) Delay before the first retry, doubled before each of the next ones, defaults to 10ms"
+	MaxDelay    time.Duration "(optional) Caps the delays, defaults to 1s"
+}
+
+//
+// Call fn until it succeeds, fails with an error that isn't worth retrying, or runs out of attempts
+//
+// A nil policy calls fn once.
+//
+func (p *RetryPolicy) do(ctx context.Context, fn func(attempt int) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if nil == err || !isRetryable(err) || !p.wait(ctx, attempt) {
+			return err
+		}
+	}
+}
+
+//
+// Wait before the next attempt, returns false when the attempts ran out or the context is done
+//
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	if nil == p || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	timer := time.NewTimer(p.delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+//
+// Delay before the retry after the attempt, half of it randomized so the clients don't retry in lock step
+//
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	base_delay, max_delay := p.BaseDelay, p.MaxDelay
+	if time.Duration(0) == base_delay {
+		base_delay = time.Duration(10) * time.Millisecond
+	}
+	if time.Duration(0) == max_delay {
+		max_delay = time.Second
+	}
+
+	delay := base_delay
+	for i := 1; i < attempt && delay < max_delay; i++ {
+		delay *= 2
+	}
+	if delay > max_delay {
+		delay = max_delay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+//
+// Did the command fail because of the network, rather than the server or the caller?
+//
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var net_err net.Error
+	return errors.Is(err, ErrConnectionIsClosed) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &net_err)
+}
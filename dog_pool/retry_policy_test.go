@@ -0,0 +1,89 @@
+package dog_pool
+
+import "context"
+import "errors"
+import "io"
+import "os"
+import "testing"
+import "time"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestRetryPolicySpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(RetryPolicySpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func RetryPolicySpecs(c gospec.Context) {
+	c.Specify("[RetryPolicy] Nil policy calls once", func() {
+		var policy *RetryPolicy
+		attempts := 0
+		err := policy.do(context.Background(), func(int) error { attempts++; return io.EOF })
+		c.Expect(err, gospec.Equals, io.EOF)
+		c.Expect(attempts, gospec.Equals, 1)
+	})
+
+	c.Specify("[RetryPolicy] Retries the network errors up to MaxAttempts", func() {
+		policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+		attempts := []int{}
+		err := policy.do(context.Background(), func(attempt int) error { attempts = append(attempts, attempt); return io.EOF })
+		c.Expect(err, gospec.Equals, io.EOF)
+		c.Expect(attempts, gospec.ContainsExactly, []int{1, 2, 3})
+
+		// Stops retrying once it succeeds
+		attempts = []int{}
+		err = policy.do(context.Background(), func(attempt int) error {
+			attempts = append(attempts, attempt)
+			if attempt < 2 {
+				return ErrConnectionIsClosed
+			}
+			return nil
+		})
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(attempts, gospec.ContainsExactly, []int{1, 2})
+	})
+
+	c.Specify("[RetryPolicy] Doesn't retry the other errors", func() {
+		policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+		for _, expected := range []error{errors.New("WRONGTYPE"), ErrCircuitOpen, context.Canceled, context.DeadlineExceeded} {
+			attempts := 0
+			err := policy.do(context.Background(), func(int) error { attempts++; return expected })
+			c.Expect(err, gospec.Equals, expected)
+			c.Expect(attempts, gospec.Equals, 1)
+		}
+
+		c.Expect(isRetryable(&TimeoutError{Op: "read", After: time.Second, Err: os.ErrDeadlineExceeded}), gospec.Equals, true)
+		c.Expect(isRetryable(io.ErrUnexpectedEOF), gospec.Equals, true)
+	})
+
+	c.Specify("[RetryPolicy] Stops waiting when the context is done", func() {
+		policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+		defer cancel()
+
+		attempts := 0
+		err := policy.do(ctx, func(int) error { attempts++; return io.EOF })
+		c.Expect(err, gospec.Equals, io.EOF)
+		c.Expect(attempts, gospec.Equals, 1)
+	})
+
+	c.Specify("[RetryPolicy] Backs off exponentially, with jitter, up to MaxDelay", func() {
+		policy := &RetryPolicy{MaxAttempts: 10, BaseDelay: time.Duration(100) * time.Millisecond, MaxDelay: time.Second}
+		for i := 0; i < 100; i++ {
+			for attempt, max_delay := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+				max_delay *= time.Millisecond
+				delay := policy.delay(attempt + 1)
+				c.Expect(delay, gospec.Satisfies, delay >= max_delay/2 && delay <= max_delay)
+			}
+		}
+
+		// Defaults to 10ms, doubled up to 1s
+		policy = &RetryPolicy{MaxAttempts: 10}
+		c.Expect(policy.delay(1), gospec.Satisfies, policy.delay(1) <= time.Duration(10)*time.Millisecond)
+		c.Expect(policy.delay(20), gospec.Satisfies, policy.delay(20) >= time.Duration(500)*time.Millisecond && policy.delay(20) <= time.Second)
+	})
+}
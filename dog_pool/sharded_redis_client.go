@@ -0,0 +1,320 @@
+//
+// Sharded Redis Client written in GO
+//
+
+package dog_pool
+
+import "errors"
+import "fmt"
+import "strings"
+import "github.com/RUNDSP/radix/redis"
+
+//
+// Number of points on the hash ring for each shard
+//
+const shard_replicas = 160
+
+//
+// Redis Client that shards the keys between several connection pools
+//
+// Commands are routed by consistent hashing of their key, like RedisClusterClient:
+// the first argument, BITOP's destkey, or the first key of EVAL/EVALSHA.
+// Keys with a {hashtag} are routed by the hashtag alone, commands without a key go to the first shard.
+//
+// MGET and DEL are split between the shards, MGET replies are reassembled in the order of the keys,
+// and the reply to a DEL is one IntegerReply with the number of keys deleted from all of the shards.
+// Other multi-key commands must share a {hashtag} to land on the same shard.
+//
+// Connections are Pop'd from the shards' pools while commands are pipelined,
+// and Push'd back when the pipeline is empty.
+// Like RedisConnection, a ShardedRedisClient is not safe for concurrent use.
+//
+type ShardedRedisClient struct {
+	Shards []*RedisConnectionPool "Open connection pools for each of the shards"
+
+	ring        *hashRing                "Maps the keys to the Shards"
+	connections map[int]*RedisConnection "Connections borrowed for the pipeline"
+	queue       []*shardedCommand        "Pipelined commands waiting for their replies"
+}
+
+//
+// Pipelined command, split into one part per shard
+//
+type shardedCommand struct {
+	cmd   string
+	split bool "Was the command split between the shards?"
+	parts []*shardedPart
+}
+
+//
+// Part of a pipelined command sent to one shard
+//
+type shardedPart struct {
+	shard   int
+	indices []int "Position of each of the part's keys in the command"
+	err     error "Error borrowing a connection for the shard"
+}
+
+//
+// Make a client for the pools, the shard of each key depends on the pools' Urls
+//
+func MakeShardedRedisClient(shards ...*RedisConnectionPool) (*ShardedRedisClient, error) {
+	if 0 == len(shards) {
+		return nil, errors.New("[ShardedRedisClient][Make] Shards must not be empty!")
+	}
+
+	names := make([]string, len(shards))
+	for i, shard := range shards {
+		names[i] = strings.Join(shard.Urls, ",")
+	}
+
+	return &ShardedRedisClient{
+		Shards:      shards,
+		ring:        makeHashRing(names, shard_replicas),
+		connections: make(map[int]*RedisConnection),
+	}, nil
+}
+
+func (p *ShardedRedisClient) String() string {
+	return fmt.Sprintf("ShardedRedisClient { Shards=%v, Pipelined=%v }", len(p.Shards), len(p.queue))
+}
+
+//
+// Index of the shard for the key
+//
+func (p *ShardedRedisClient) ShardOf(key string) int {
+	return p.ring.ownerOf(key)
+}
+
+//
+//  ========================================
+//
+// RedisClientInterface implementation:
+//
+//  ========================================
+//
+
+//
+// Close discards the pipelined commands, and Push'es the borrowed connections back to their pools.
+// Connections with unread replies are closed first.
+//
+func (p *ShardedRedisClient) Close() error {
+	if 0 != len(p.queue) {
+		for _, c := range p.connections {
+			c.Close()
+		}
+	}
+
+	p.queue = nil
+	p.release()
+	return nil
+}
+
+//
+// Cmd calls the given Redis command on the key's shard:
+// - Calls Append(...)
+// - Returns GetReply()
+//
+func (p *ShardedRedisClient) Cmd(cmd string, args ...interface{}) *redis.Reply {
+	p.Append(cmd, args...)
+	return p.GetReply()
+}
+
+//
+// Append adds the given call to the pipeline queue of the key's shard.
+// Use GetReply() to read the reply.
+//
+func (p *ShardedRedisClient) Append(cmd string, args ...interface{}) {
+	args = flattenArgs(args)
+	command := &shardedCommand{cmd: cmd}
+
+	switch upper := strings.ToUpper(cmd); {
+	case len(args) > 0 && (cmd_mget == upper || cmd_del == upper):
+		// Group the keys by shard, in the order the shards are first seen
+		parts := make(map[int]*shardedPart)
+		part_args := make(map[int][]interface{})
+		for i, arg := range args {
+			shard := p.ShardOf(argString(arg))
+			part, ok := parts[shard]
+			if !ok {
+				part = &shardedPart{shard: shard}
+				parts[shard] = part
+				command.parts = append(command.parts, part)
+			}
+			part.indices = append(part.indices, i)
+			part_args[shard] = append(part_args[shard], arg)
+		}
+
+		command.split = true
+		for _, part := range command.parts {
+			p.appendPart(part, cmd, part_args[part.shard])
+		}
+
+	default:
+		shard := 0
+		if key := commandKey(cmd, args); "" != key {
+			shard = p.ShardOf(key)
+		}
+
+		part := &shardedPart{shard: shard}
+		command.parts = []*shardedPart{part}
+		p.appendPart(part, cmd, args)
+	}
+
+	p.queue = append(p.queue, command)
+}
+
+//
+// GetReply returns the reply for the next request in the pipeline queue.
+// Error reply with PipelineQueueEmptyError is returned,
+// if the pipeline queue is empty.
+//
+func (p *ShardedRedisClient) GetReply() *redis.Reply {
+	if 0 == len(p.queue) {
+		return &redis.Reply{Type: redis.ErrorReply, Err: redis.PipelineQueueEmptyError}
+	}
+
+	command := p.queue[0]
+	p.queue = p.queue[1:]
+
+	// Read every part's reply, so the shards' pipelines stay in order
+	replies := make([]*redis.Reply, len(command.parts))
+	for i, part := range command.parts {
+		if nil != part.err {
+			replies[i] = &redis.Reply{Type: redis.ErrorReply, Err: part.err}
+		} else {
+			replies[i] = p.connections[part.shard].GetReply()
+		}
+	}
+
+	// Return the connections once the pipeline is empty
+	if 0 == len(p.queue) {
+		p.release()
+	}
+
+	return command.reassemble(replies)
+}
+
+//
+//  ========================================
+//
+// ShardedRedisClient Utils:
+//
+//  ========================================
+//
+
+//
+// Append the part to its shard's connection, borrowing one if necessary
+//
+func (p *ShardedRedisClient) appendPart(part *shardedPart, cmd string, args []interface{}) {
+	c, ok := p.connections[part.shard]
+	if !ok {
+		var err error
+		c, err = p.Shards[part.shard].Pop()
+		if nil != err {
+			part.err = err
+			return
+		}
+		p.connections[part.shard] = c
+	}
+
+	c.Append(cmd, args...)
+}
+
+//
+// Push the borrowed connections back to their pools
+//
+func (p *ShardedRedisClient) release() {
+	for shard, c := range p.connections {
+		p.Shards[shard].Push(c)
+		delete(p.connections, shard)
+	}
+}
+
+//
+// Combine the replies from each of the parts
+//
+func (p *shardedCommand) reassemble(replies []*redis.Reply) *redis.Reply {
+	if !p.split {
+		return replies[0]
+	}
+
+	// Any error fails the whole command
+	for _, reply := range replies {
+		if redis.ErrorReply == reply.Type {
+			return reply
+		}
+	}
+
+	// Add up the keys each shard deleted
+	if cmd_del == strings.ToUpper(p.cmd) {
+		if 1 == len(replies) {
+			return replies[0]
+		}
+
+		total := int64(0)
+		for i, reply := range replies {
+			count, err := reply.Int64()
+			if nil != err {
+				return &redis.Reply{Type: redis.ErrorReply, Err: fmt.Errorf("[ShardedRedisClient][GetReply] Expected an integer from shard[%v], Error = %v", p.parts[i].shard, err)}
+			}
+			total += count
+		}
+		return makeIntegerReply(total)
+	}
+
+	// Put the values back in the order of the keys
+	count := 0
+	for _, part := range p.parts {
+		count += len(part.indices)
+	}
+
+	elems := make([]*redis.Reply, count)
+	for i, part := range p.parts {
+		if len(replies[i].Elems) != len(part.indices) {
+			return &redis.Reply{Type: redis.ErrorReply, Err: fmt.Errorf("[ShardedRedisClient][GetReply] Expected %v replies from shard[%v], Actual=%v", len(part.indices), part.shard, len(replies[i].Elems))}
+		}
+		for j, index := range part.indices {
+			elems[index] = replies[i].Elems[j]
+		}
+	}
+	return &redis.Reply{Type: redis.MultiReply, Elems: elems}
+}
+
+//
+// Flatten the slices of arguments, like the ones RedisBatchCommand appends
+//
+func flattenArgs(args []interface{}) []interface{} {
+	output := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch values := arg.(type) {
+		case [][]byte:
+			for _, value := range values {
+				output = append(output, value)
+			}
+		case []string:
+			for _, value := range values {
+				output = append(output, value)
+			}
+		case []interface{}:
+			output = append(output, flattenArgs(values)...)
+		default:
+			output = append(output, arg)
+		}
+	}
+	return output
+}
+
+//
+// Argument as a string, for hashing the keys
+//
+func argString(arg interface{}) string {
+	switch value := arg.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,256 @@
+package dog_pool
+
+import "errors"
+import "fmt"
+import "testing"
+import "log"
+import "github.com/RUNDSP/radix/redis"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestShardedRedisClientSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(ShardedRedisClientSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func ShardedRedisClientSpecs(c gospec.Context) {
+	logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+
+	// LAZY pools for invalid urls, the commands fail but the routing still works
+	makeShards := func() []*RedisConnectionPool {
+		shards := []*RedisConnectionPool{
+			&RedisConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:6991"}, Logger: logger},
+			&RedisConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:6992"}, Logger: logger},
+			&RedisConnectionPool{Mode: LAZY, Size: 2, Urls: []string{"127.0.0.1:6993"}, Logger: logger},
+		}
+		for _, shard := range shards {
+			if err := shard.Open(); nil != err {
+				panic(err)
+			}
+		}
+		return shards
+	}
+
+	c.Specify("[ShardedRedisClient] Requires at least one shard", func() {
+		client, err := MakeShardedRedisClient()
+		c.Expect(err.Error(), gospec.Equals, "[ShardedRedisClient][Make] Shards must not be empty!")
+		c.Expect(client, gospec.Satisfies, nil == client)
+	})
+
+	c.Specify("[ShardedRedisClient] Routes the commands by key", func() {
+		shards := makeShards()
+		client, _ := MakeShardedRedisClient(shards...)
+
+		key := "bob"
+		shard := client.ShardOf(key)
+		client.Append("GET", key)
+		c.Expect(len(client.queue), gospec.Equals, 1)
+		c.Expect(client.queue[0].parts[0].shard, gospec.Equals, shard)
+		c.Expect(shards[shard].Len(), gospec.Equals, 1)
+
+		// The connection is returned after the last reply
+		reply := client.GetReply()
+		c.Expect(reply.Err, gospec.Equals, ErrConnectionIsClosed)
+		c.Expect(shards[shard].Len(), gospec.Equals, 2)
+
+		reply = client.GetReply()
+		c.Expect(reply.Err, gospec.Equals, redis.PipelineQueueEmptyError)
+	})
+
+	c.Specify("[ShardedRedisClient] Routes BITOP and EVAL by their keys", func() {
+		shards := makeShards()
+		client, _ := MakeShardedRedisClient(shards...)
+		defer client.Close()
+
+		// A key on each of the shards
+		keys := map[int]string{}
+		for i := 0; len(keys) < len(shards); i++ {
+			key := fmt.Sprintf("key:%d", i)
+			if _, ok := keys[client.ShardOf(key)]; !ok {
+				keys[client.ShardOf(key)] = key
+			}
+		}
+
+		for shard, key := range keys {
+			client.Append("BITOP", "AND", key, key+":a", key+":b")
+			MakeRedisBatchCommandBitopNot(key, key+":a").RedisAppend(client)
+			client.Append("EVAL", "return 1", 1, key, "arg")
+			client.Append("EVALSHA", "abc", "1", key)
+
+			for _, command := range client.queue[len(client.queue)-4:] {
+				c.Expect(command.parts[0].shard, gospec.Equals, shard)
+			}
+		}
+
+		// Scripts without keys go to the first shard
+		client.Append("EVAL", "return 1", 0)
+		c.Expect(client.queue[len(client.queue)-1].parts[0].shard, gospec.Equals, 0)
+	})
+
+	c.Specify("[ShardedRedisClient] Splits MGET from a batch command between the shards", func() {
+		shards := makeShards()
+		client, _ := MakeShardedRedisClient(shards...)
+
+		keys := []string{}
+		for i := 0; i < 20; i++ {
+			keys = append(keys, fmt.Sprintf("key:%d", i))
+		}
+		MakeRedisBatchCommandMget(keys...).RedisAppend(client)
+
+		command := client.queue[0]
+		c.Expect(command.split, gospec.Equals, true)
+		c.Expect(len(command.parts), gospec.Equals, 3)
+
+		seen := make([]bool, len(keys))
+		for _, part := range command.parts {
+			for _, index := range part.indices {
+				c.Expect(client.ShardOf(keys[index]), gospec.Equals, part.shard)
+				seen[index] = true
+			}
+		}
+		for i := range keys {
+			c.Expect(seen[i], gospec.Equals, true)
+		}
+
+		client.Close()
+		for _, shard := range shards {
+			c.Expect(shard.Len(), gospec.Equals, 2)
+		}
+	})
+
+	c.Specify("[ShardedRedisClient] Reassembles MGET replies in the order of the keys", func() {
+		command := &shardedCommand{cmd: "MGET", split: true, parts: []*shardedPart{
+			&shardedPart{shard: 1, indices: []int{0, 2}},
+			&shardedPart{shard: 0, indices: []int{1}},
+		}}
+
+		// Tag each value with its key's position
+		value := func(i int) *redis.Reply { return &redis.Reply{Type: redis.BulkReply, Err: fmt.Errorf("%d", i)} }
+		reply := command.reassemble([]*redis.Reply{
+			&redis.Reply{Type: redis.MultiReply, Elems: []*redis.Reply{value(0), value(2)}},
+			&redis.Reply{Type: redis.MultiReply, Elems: []*redis.Reply{value(1)}},
+		})
+
+		c.Expect(reply.Type, gospec.Equals, redis.MultiReply)
+		c.Expect(len(reply.Elems), gospec.Equals, 3)
+		for i, elem := range reply.Elems {
+			c.Expect(elem.Err.Error(), gospec.Equals, fmt.Sprintf("%d", i))
+		}
+	})
+
+	c.Specify("[ShardedRedisClient] Errors from any shard fail the command", func() {
+		command := &shardedCommand{cmd: "DEL", split: true, parts: []*shardedPart{
+			&shardedPart{shard: 1, indices: []int{0}},
+			&shardedPart{shard: 0, indices: []int{1}},
+		}}
+
+		expected := errors.New("Shard Error")
+		reply := command.reassemble([]*redis.Reply{
+			&redis.Reply{Type: redis.IntegerReply},
+			&redis.Reply{Type: redis.ErrorReply, Err: expected},
+		})
+		c.Expect(reply.Err, gospec.Equals, expected)
+
+		reply = command.reassemble([]*redis.Reply{
+			makeIntegerReply(1),
+			makeIntegerReply(0),
+		})
+		c.Expect(reply.Type, gospec.Equals, redis.IntegerReply)
+	})
+
+	c.Specify("[ShardedRedisClient] Adds up the keys a split DEL deleted", func() {
+		command := &shardedCommand{cmd: "DEL", split: true, parts: []*shardedPart{
+			&shardedPart{shard: 2, indices: []int{0, 3}},
+			&shardedPart{shard: 0, indices: []int{1}},
+			&shardedPart{shard: 1, indices: []int{2}},
+		}}
+
+		reply := command.reassemble([]*redis.Reply{
+			makeIntegerReply(2),
+			makeIntegerReply(0),
+			makeIntegerReply(1),
+		})
+		c.Expect(reply.Type, gospec.Equals, redis.IntegerReply)
+		count, err := reply.Int64()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(count, gospec.Equals, int64(3))
+	})
+
+	c.Specify("[ShardedRedisClient] MGET's across Redis servers", func() {
+		servers := []*RedisServerProcess{}
+		shards := []*RedisConnectionPool{}
+		for i := 0; i < 2; i++ {
+			server, err := StartRedisServer(logger)
+			if nil != err {
+				panic(err)
+			}
+			defer server.Close()
+			servers = append(servers, server)
+
+			shard := &RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: logger}
+			c.Expect(shard.Open(), gospec.Equals, nil)
+			defer shard.Close()
+			shards = append(shards, shard)
+		}
+
+		client, _ := MakeShardedRedisClient(shards...)
+		keys := []string{}
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("key:%d", i)
+			keys = append(keys, key)
+			c.Expect(client.Cmd("SET", key, fmt.Sprintf("value:%d", i)).Err, gospec.Equals, nil)
+		}
+
+		command := MakeRedisBatchCommandMget(keys...)
+		c.Expect(RedisBatchCommands{command}.ExecuteBatch(client), gospec.Equals, nil)
+
+		values, err := command.ReplyToStringPtrs()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(len(values), gospec.Equals, len(keys))
+		for i, value := range values {
+			c.Expect(*value, gospec.Equals, fmt.Sprintf("value:%d", i))
+		}
+
+		// The keys are spread over both servers, the DEL counts all of them
+		used := map[int]bool{}
+		for _, key := range keys {
+			used[client.ShardOf(key)] = true
+		}
+		c.Expect(len(used), gospec.Equals, 2)
+		deleted, err := client.Cmd("DEL", keys[0], keys[1], keys[2], keys[3], keys[4], keys[5], keys[6], keys[7], keys[8], keys[9], "missing").Int()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(deleted, gospec.Equals, len(keys))
+	})
+
+	c.Specify("[ShardedRedisClient] Close doesn't leave unread replies on the connections", func() {
+		server, err := StartRedisServer(logger)
+		if nil != err {
+			panic(err)
+		}
+		defer server.Close()
+
+		shard := &RedisConnectionPool{Mode: AGRESSIVE, Size: 1, Urls: []string{server.Connection().Url}, Logger: logger}
+		c.Expect(shard.Open(), gospec.Equals, nil)
+		defer shard.Close()
+
+		client, _ := MakeShardedRedisClient(shard)
+		c.Expect(client.Cmd("SET", "bob", "stale").Err, gospec.Equals, nil)
+		c.Expect(client.Cmd("SET", "alice", "fresh").Err, gospec.Equals, nil)
+
+		// Close with the GET's reply unread
+		client.Append("GET", "bob")
+		c.Expect(client.Close(), gospec.Equals, nil)
+
+		connection, err := shard.Pop()
+		c.Expect(err, gospec.Equals, nil)
+		defer shard.Push(connection)
+
+		value, err := connection.Cmd("GET", "alice").Str()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value, gospec.Equals, "fresh")
+	})
+}
@@ -2,8 +2,8 @@ package dog_pool
 
 import "time"
 import "testing"
+import "log"
 import "github.com/orfjackal/gospec/src/gospec"
-import "github.com/alecthomas/log4go"
 
 func TestStopWatchSpecs(t *testing.T) {
 	if testing.Short() {
@@ -19,10 +19,10 @@ func TestStopWatchSpecs(t *testing.T) {
 func StopWatchSpecs(c gospec.Context) {
 
 	c.Specify("[StopWatch] Makes StopWatch", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		value := MakeStopWatch(c, &logger, "Make")
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		value := MakeStopWatch(c, logger, "Make")
 		c.Expect(value, gospec.Satisfies, nil != value)
-		c.Expect(value.Logger, gospec.Equals, &logger)
+		c.Expect(value.Logger, gospec.Equals, logger)
 		c.Expect(value.Connection, gospec.Equals, c)
 		c.Expect(value.Tags[0], gospec.Equals, "Make")
 		c.Expect(value.Time, gospec.Satisfies, value.Time.IsZero())
@@ -30,10 +30,10 @@ func StopWatchSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[StopWatch] Starts StopWatch", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		value := MakeStopWatch(c, &logger, "Make")
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		value := MakeStopWatch(c, logger, "Make")
 		c.Expect(value, gospec.Satisfies, nil != value)
-		c.Expect(value.Logger, gospec.Equals, &logger)
+		c.Expect(value.Logger, gospec.Equals, logger)
 		c.Expect(value.Connection, gospec.Equals, c)
 		c.Expect(value.Tags[0], gospec.Equals, "Make")
 		c.Expect(value.Time, gospec.Satisfies, value.Time.IsZero())
@@ -47,10 +47,10 @@ func StopWatchSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[StopWatch] Stops StopWatch", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		value := MakeStopWatch(c, &logger, "Make")
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		value := MakeStopWatch(c, logger, "Make")
 		c.Expect(value, gospec.Satisfies, nil != value)
-		c.Expect(value.Logger, gospec.Equals, &logger)
+		c.Expect(value.Logger, gospec.Equals, logger)
 		c.Expect(value.Connection, gospec.Equals, c)
 		c.Expect(value.Tags[0], gospec.Equals, "Make")
 		c.Expect(value.Time, gospec.Satisfies, value.Time.IsZero())
@@ -75,10 +75,10 @@ func StopWatchSpecs(c gospec.Context) {
 	})
 
 	c.Specify("[StopWatch] Logs StopWatch", func() {
-		logger := log4go.NewDefaultLogger(log4go.CRITICAL)
-		value := MakeStopWatch(c, &logger, "Make")
+		logger := MakeStdLogger(log.Default(), LOG_CRITICAL)
+		value := MakeStopWatch(c, logger, "Make")
 		c.Expect(value, gospec.Satisfies, nil != value)
-		c.Expect(value.Logger, gospec.Equals, &logger)
+		c.Expect(value.Logger, gospec.Equals, logger)
 		c.Expect(value.Connection, gospec.Equals, c)
 		c.Expect(value.Tags[0], gospec.Equals, "Make")
 		c.Expect(value.Time, gospec.Satisfies, value.Time.IsZero())
@@ -0,0 +1,74 @@
+//
+// Dial, read and write timeouts written in GO
+//
+
+package dog_pool
+
+import "errors"
+import "fmt"
+import "net"
+import "time"
+
+//
+// Dialing, reading or writing took longer than its timeout
+//
+// TimeoutError is a net.Error, and unwraps to the socket's error.
+//
+type TimeoutError struct {
+	Op    string        "dial, read or write"
+	After time.Duration "Timeout that expired"
+	Err   error         "Error from the socket"
+}
+
+func (p *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %v, Error = %v", p.Op, p.After, p.Err)
+}
+
+func (p *TimeoutError) Unwrap() error {
+	return p.Err
+}
+
+func (p *TimeoutError) Timeout() bool {
+	return true
+}
+
+func (p *TimeoutError) Temporary() bool {
+	return true
+}
+
+//
+// Timeouts of a connection, the Dial, Read and Write timeouts fall back to Timeout
+//
+type connectionTimeouts struct {
+	Timeout time.Duration "Timeout for dialing, reading and writing"
+	Dial    time.Duration "(optional) Timeout for dialing"
+	Read    time.Duration "(optional) Timeout for reading each reply"
+	Write   time.Duration "(optional) Timeout for writing each command"
+}
+
+func (p connectionTimeouts) dial() time.Duration {
+	return orTimeout(p.Dial, p.Timeout)
+}
+
+func (p connectionTimeouts) read() time.Duration {
+	return orTimeout(p.Read, p.Timeout)
+}
+
+func (p connectionTimeouts) write() time.Duration {
+	return orTimeout(p.Write, p.Timeout)
+}
+
+func orTimeout(timeout, fallback time.Duration) time.Duration {
+	if time.Duration(0) == timeout {
+		return fallback
+	}
+	return timeout
+}
+
+//
+// Did the socket's deadline pass?
+//
+func isTimeout(err error) bool {
+	var net_err net.Error
+	return errors.As(err, &net_err) && net_err.Timeout()
+}
@@ -0,0 +1,42 @@
+package dog_pool
+
+import "errors"
+import "net"
+import "os"
+import "testing"
+import "time"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestTimeoutsSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(TimeoutsSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func TimeoutsSpecs(c gospec.Context) {
+	c.Specify("[Timeouts] Dial, Read and Write timeouts fall back to the Timeout", func() {
+		timeouts := connectionTimeouts{Timeout: time.Second}
+		c.Expect(timeouts.dial(), gospec.Equals, time.Second)
+		c.Expect(timeouts.read(), gospec.Equals, time.Second)
+		c.Expect(timeouts.write(), gospec.Equals, time.Second)
+
+		timeouts = connectionTimeouts{Timeout: time.Second, Dial: time.Minute, Read: time.Hour, Write: time.Millisecond}
+		c.Expect(timeouts.dial(), gospec.Equals, time.Minute)
+		c.Expect(timeouts.read(), gospec.Equals, time.Hour)
+		c.Expect(timeouts.write(), gospec.Equals, time.Millisecond)
+	})
+
+	c.Specify("[Timeouts] TimeoutError is a net.Error, and unwraps to the socket's error", func() {
+		var err error = &TimeoutError{Op: "read", After: time.Second, Err: os.ErrDeadlineExceeded}
+		c.Expect(err.Error(), gospec.Equals, "read timed out after 1s, Error = i/o timeout")
+		c.Expect(isTimeout(err), gospec.Equals, true)
+		c.Expect(errors.Is(err, os.ErrDeadlineExceeded), gospec.Equals, true)
+
+		var net_err net.Error
+		c.Expect(errors.As(err, &net_err), gospec.Equals, true)
+	})
+}
@@ -0,0 +1,69 @@
+package dog_pool
+
+import "crypto/ecdsa"
+import "crypto/elliptic"
+import "crypto/rand"
+import "crypto/tls"
+import "crypto/x509"
+import "crypto/x509/pkix"
+import "encoding/pem"
+import "math/big"
+import "net"
+import "os"
+import "path/filepath"
+import "time"
+
+//
+// Self-signed certificate for 127.0.0.1 and localhost, for starting TLS servers in the specs
+//
+type SelfSignedCert struct {
+	CertFile     string      "PEM certificate, also the CA to verify it with"
+	KeyFile      string      "PEM private key"
+	ClientConfig *tls.Config "Trusts only the certificate"
+}
+
+//
+// Write a new self-signed certificate and its key to the directory
+//
+func MakeSelfSignedCert(dir string) (*SelfSignedCert, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if nil != err {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "dog_pool"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if nil != err {
+		return nil, err
+	}
+	key_der, err := x509.MarshalECPrivateKey(key)
+	if nil != err {
+		return nil, err
+	}
+
+	output := &SelfSignedCert{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	cert_pem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(output.CertFile, cert_pem, 0600); nil != err {
+		return nil, err
+	}
+	if err := os.WriteFile(output.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key_der}), 0600); nil != err {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(cert_pem)
+	output.ClientConfig = &tls.Config{RootCAs: roots}
+
+	return output, nil
+}
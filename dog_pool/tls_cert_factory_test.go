@@ -0,0 +1,50 @@
+package dog_pool
+
+import "crypto/tls"
+import "os"
+import "testing"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestSelfSignedCertSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(SelfSignedCertSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func SelfSignedCertSpecs(c gospec.Context) {
+	c.Specify("[SelfSignedCert] Clients trust the certificate", func() {
+		dir, err := os.MkdirTemp("", "dog_pool")
+		if nil != err {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		cert, err := MakeSelfSignedCert(dir)
+		c.Expect(err, gospec.Equals, nil)
+
+		pair, err := tls.LoadX509KeyPair(cert.CertFile, cert.KeyFile)
+		c.Expect(err, gospec.Equals, nil)
+
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{pair}})
+		if nil != err {
+			panic(err)
+		}
+		defer listener.Close()
+		go func() {
+			if conn, err := listener.Accept(); nil == err {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}
+		}()
+
+		conn, err := tls.Dial("tcp", listener.Addr().String(), cert.ClientConfig)
+		c.Expect(err, gospec.Equals, nil)
+		if nil == err {
+			conn.Close()
+		}
+	})
+}
@@ -1,15 +1,75 @@
 package dog_pool
 
+import "context"
+import "fmt"
+import "net"
 import "strconv"
+import "strings"
+import "sync"
+import "time"
+import "github.com/RUNDSP/radix/redis"
 
 //
-// Helper to iterate urls
+// Helper to iterate urls, safe to call from multiple go routines
 //
 func loopStrings(values []string) func() []string {
+	var mutex sync.Mutex
 	i := 0
 	return func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+
 		value := values[i%len(values)]
 		i++
 		return []string{value, strconv.Itoa(i)}
 	}
 }
+
+//
+// Map the context's error to the pool's errors
+//
+func contextError(ctx context.Context) error {
+	if context.DeadlineExceeded == ctx.Err() {
+		return ErrPoolTimeout
+	}
+	return ctx.Err()
+}
+
+//
+// Map a recovered panic to an error
+//
+func panicError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
+
+//
+// IntegerReply of n
+//
+// radix keeps the value of a reply unexported, so the reply is parsed from ":n\r\n" in memory.
+//
+func makeIntegerReply(n int64) *redis.Reply {
+	client, err := redis.NewClient(&replyConn{reader: strings.NewReader(fmt.Sprintf(":%d\r\n", n))})
+	if nil != err {
+		return &redis.Reply{Type: redis.ErrorReply, Err: err}
+	}
+	return client.ReadReply()
+}
+
+//
+// Read-only in-memory connection, radix parses replies from its reader
+//
+type replyConn struct {
+	reader *strings.Reader
+}
+
+func (p *replyConn) Read(b []byte) (int, error)         { return p.reader.Read(b) }
+func (p *replyConn) Write(b []byte) (int, error)        { return 0, ErrConnectionIsClosed }
+func (p *replyConn) Close() error                       { return nil }
+func (p *replyConn) LocalAddr() net.Addr                { return nil }
+func (p *replyConn) RemoteAddr() net.Addr               { return nil }
+func (p *replyConn) SetDeadline(t time.Time) error      { return nil }
+func (p *replyConn) SetReadDeadline(t time.Time) error  { return nil }
+func (p *replyConn) SetWriteDeadline(t time.Time) error { return nil }
@@ -0,0 +1,116 @@
+//
+// Warm-up policies for the AGRESSIVE connection pools
+//
+
+package dog_pool
+
+import "fmt"
+import "sort"
+import "sync"
+
+//
+// Which connections opened while the pool was warming up?
+//
+type WarmUpReport struct {
+	Opened int              "Number of connections that opened"
+	Failed int              "Number of connections that failed to open"
+	Errors map[string]error "Last error from each URL that failed to open"
+}
+
+func (p WarmUpReport) String() string {
+	return fmt.Sprintf("WarmUpReport { Opened=%v, Failed=%v, FailedUrls=%v }", p.Opened, p.Failed, p.FailedUrls())
+}
+
+//
+// URLs that failed to open, in sorted order
+//
+func (p WarmUpReport) FailedUrls() []string {
+	output := make([]string, 0, len(p.Errors))
+	for url := range p.Errors {
+		output = append(output, url)
+	}
+	sort.Strings(output)
+	return output
+}
+
+//
+// Fraction of the connections that opened, 1 if there were no connections
+//
+func (p WarmUpReport) Healthy() float64 {
+	if 0 == p.Opened+p.Failed {
+		return 1
+	}
+	return float64(p.Opened) / float64(p.Opened+p.Failed)
+}
+
+//
+// Collects the WarmUpReport while the pool is opening
+//
+type warmUp struct {
+	policy  WarmUpPolicy
+	redact  func(url string) string "(optional) Hides the passwords in the report's urls"
+	mutex   sync.Mutex
+	warming bool
+	report  WarmUpReport
+}
+
+func makeWarmUp(policy WarmUpPolicy, redact func(url string) string) *warmUp {
+	return &warmUp{policy: policy, redact: redact, warming: true, report: WarmUpReport{Errors: make(map[string]error)}}
+}
+
+//
+// Record the connection to url, returns false once the pool is warm
+//
+func (p *warmUp) add(url string, err error) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch {
+	case !p.warming:
+		return false
+	case nil == err:
+		p.report.Opened++
+	default:
+		if nil != p.redact {
+			url = p.redact(url)
+		}
+		p.report.Failed++
+		p.report.Errors[url] = err
+	}
+	return true
+}
+
+//
+// Stop recording, and check the report against the policy
+//
+func (p *warmUp) finish(min_healthy float64) (WarmUpReport, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.warming = false
+	if WARMUP_MIN_HEALTHY == p.policy && p.report.Healthy() < min_healthy {
+		return p.report, ErrWarmUpFailed
+	}
+	return p.report, nil
+}
+
+//
+// Wrap the AGRESSIVE factory, recording the connections while the pool is warming up,
+// and replacing the failed ones with LAZY connections unless the policy is WARMUP_FAIL_FAST
+//
+func makeWarmUpFactory[T any](w *warmUp, nextUrl func() []string, agressive, lazy func(url, id string) (T, error)) func() (T, error) {
+	return func() (T, error) {
+		values := nextUrl()
+		c, err := agressive(values[0], values[1])
+
+		// Already warm
+		if !w.add(values[0], err) {
+			return c, err
+		}
+
+		if nil != err && WARMUP_FAIL_FAST != w.policy {
+			return lazy(values[0], values[1])
+		}
+		return c, err
+	}
+}
@@ -0,0 +1,87 @@
+package dog_pool
+
+import "errors"
+import "testing"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestWarmUpSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(WarmUpSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func WarmUpSpecs(c gospec.Context) {
+	expected := errors.New("Dial Error")
+
+	// Fake factories, the "bad" url fails to open
+	agressive := func(url, id string) (*stringWrapper, error) {
+		if "bad" == url {
+			return nil, expected
+		}
+		return &stringWrapper{Value: "agressive"}, nil
+	}
+	lazy := func(url, id string) (*stringWrapper, error) {
+		return &stringWrapper{Value: "lazy"}, nil
+	}
+
+	c.Specify("[WarmUp] WARMUP_FAIL_FAST returns the error and records the url", func() {
+		warm_up := makeWarmUp(WARMUP_FAIL_FAST, nil)
+		factory := makeWarmUpFactory(warm_up, loopStrings([]string{"good", "bad"}), agressive, lazy)
+
+		value, err := factory()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value.Value, gospec.Equals, "agressive")
+
+		_, err = factory()
+		c.Expect(err, gospec.Equals, expected)
+
+		report, err := warm_up.finish(0)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(report.Opened, gospec.Equals, 1)
+		c.Expect(report.Failed, gospec.Equals, 1)
+		c.Expect(report.Errors["bad"], gospec.Equals, expected)
+		c.Expect(report.FailedUrls(), gospec.Satisfies, 1 == len(report.FailedUrls()) && "bad" == report.FailedUrls()[0])
+	})
+
+	c.Specify("[WarmUp] WARMUP_LAZY_FILL replaces the failed connections with lazy connections", func() {
+		warm_up := makeWarmUp(WARMUP_LAZY_FILL, nil)
+		factory := makeWarmUpFactory(warm_up, loopStrings([]string{"bad"}), agressive, lazy)
+
+		value, err := factory()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value.Value, gospec.Equals, "lazy")
+
+		report, err := warm_up.finish(1)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(report.Healthy(), gospec.Equals, float64(0))
+	})
+
+	c.Specify("[WarmUp] WARMUP_MIN_HEALTHY fails when too few connections opened", func() {
+		warm_up := makeWarmUp(WARMUP_MIN_HEALTHY, nil)
+		factory := makeWarmUpFactory(warm_up, loopStrings([]string{"good", "bad", "good", "bad"}), agressive, lazy)
+		for i := 0; i < 4; i++ {
+			_, err := factory()
+			c.Expect(err, gospec.Equals, nil)
+		}
+
+		report, err := warm_up.finish(0.75)
+		c.Expect(err, gospec.Equals, ErrWarmUpFailed)
+		c.Expect(report.Healthy(), gospec.Equals, float64(0.5))
+	})
+
+	c.Specify("[WarmUp] Factory errors after warming up are returned, and not recorded", func() {
+		warm_up := makeWarmUp(WARMUP_LAZY_FILL, nil)
+		factory := makeWarmUpFactory(warm_up, loopStrings([]string{"bad"}), agressive, lazy)
+		warm_up.finish(0)
+
+		_, err := factory()
+		c.Expect(err, gospec.Equals, expected)
+
+		report, _ := warm_up.finish(0)
+		c.Expect(report.Failed, gospec.Equals, 0)
+	})
+}
@@ -0,0 +1,472 @@
+//
+// Per-URL sub-pools with weighted distribution
+//
+
+package dog_pool
+
+import "context"
+import "errors"
+import "fmt"
+import "math/rand"
+import "reflect"
+import "sort"
+import "strconv"
+import "strings"
+import "sync/atomic"
+import "time"
+
+//
+// Pool of objects for one URL
+//
+type subPool[T comparable] struct {
+	url     string
+	weight  int
+	pool    *Pool[T]
+	breaker *circuitBreaker "(optional) Skip the sub-pool while its circuit breaker is open, may be nil"
+}
+
+//
+// One Pool per URL, Get picks a sub-pool by weight from the ones with a free slot
+//
+// The Size, MinIdle and MaxOpen are split between the sub-pools by weight,
+// sub-pools with an open circuit breaker are skipped.
+//
+type weightedPool[T comparable] struct {
+	pools    []*subPool[T]
+	byUrl    map[string]*subPool[T]
+	urlOf    func(T) string
+	rejected atomic.Uint64 "Number of Get's that failed fast because every circuit breaker was open"
+	ketama   *hashRing     "(optional) Maps the keys to the sub-pools, see useKetama"
+}
+
+//
+// Parse "host:port;weight=3" into the URL and its weight, the weight defaults to 1
+//
+// Only a weight after the last ';' is parsed, anything else is part of the URL,
+// so a ';' in a redis:// password doesn't cut the URL short.
+//
+func parseWeightedUrl(value string) (string, int, error) {
+	i := strings.LastIndex(value, ";")
+	if i < 0 {
+		return value, 1, nil
+	}
+
+	key, val, found := strings.Cut(value[i+1:], "=")
+	if !found || "weight" != key {
+		return value, 1, nil
+	}
+
+	weight, err := strconv.Atoi(val)
+	if nil != err || weight < 1 {
+		return "", 0, fmt.Errorf("[Pool][Open] Url[%v] weight must be an integer >= 1!", redactRedisUrl(value))
+	}
+	return value[:i], weight, nil
+}
+
+//
+// Split n between the weights, giving the remainder to the largest fractions
+//
+func splitByWeight(n int, weights []int) []int {
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+
+	output := make([]int, len(weights))
+	remainders := make([]int, len(weights))
+	left := n
+	for i, weight := range weights {
+		output[i] = n * weight / total
+		remainders[i] = n * weight % total
+		left -= output[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; i < left; i++ {
+		output[order[i]]++
+	}
+	return output
+}
+
+//
+// Create a sub-pool for each of the urls, newPool configures everything but the sizes
+// and returns the url's circuit breaker, or nil
+//
+// Repeated urls are merged, adding up their weights.
+//
+func makeWeightedPool[T comparable](urls []string, size, min_idle, max_open int, urlOf func(T) string, newPool func(url string) (*Pool[T], *circuitBreaker)) (*weightedPool[T], error) {
+	limit := max_open
+	if 0 == max_open {
+		limit = size
+	}
+
+	switch {
+	case 0 == len(urls) && (size > 0 || max_open > 0):
+		return nil, errors.New("[Pool][Open] Empty Urls!")
+	case 0 == len(urls):
+		// An empty pool still opens, with one empty sub-pool
+		urls = []string{""}
+	case size < 0:
+		return nil, fmt.Errorf("[Pool][Open] Size[%v] must be >= 0!", size)
+	case max_open < 0:
+		return nil, fmt.Errorf("[Pool][Open] MaxOpen[%v] must be >= 0!", max_open)
+	case min_idle < 0 || min_idle > limit:
+		return nil, fmt.Errorf("[Pool][Open] MinIdle[%v] must be >= 0 and <= MaxOpen[%v]!", min_idle, limit)
+	}
+
+	p := &weightedPool[T]{byUrl: make(map[string]*subPool[T]), urlOf: urlOf}
+	for _, value := range urls {
+		url, weight, err := parseWeightedUrl(value)
+		if nil != err {
+			return nil, err
+		}
+
+		if sub, ok := p.byUrl[url]; ok {
+			sub.weight += weight
+			continue
+		}
+
+		pool, breaker := newPool(url)
+		sub := &subPool[T]{url: url, weight: weight, pool: pool, breaker: breaker}
+		p.pools = append(p.pools, sub)
+		p.byUrl[url] = sub
+	}
+
+	weights := make([]int, len(p.pools))
+	for i, sub := range p.pools {
+		weights[i] = sub.weight
+	}
+
+	sizes := splitByWeight(size, weights)
+	min_idles := splitByWeight(min_idle, weights)
+	max_opens := splitByWeight(max_open, weights)
+	for i, sub := range p.pools {
+		sub.pool.Size = sizes[i]
+		sub.pool.MaxOpen = max_opens[i]
+		sub.pool.MinIdle = min_idles[i]
+
+		// Splitting by weight doesn't always round MinIdle and MaxOpen the same way,
+		// and a sub-pool with no share of MaxOpen must not fall back to its Size
+		if max_open > 0 && sub.pool.MinIdle > sub.pool.MaxOpen {
+			sub.pool.MinIdle = sub.pool.MaxOpen
+		}
+		if max_open > 0 && 0 == sub.pool.MaxOpen {
+			sub.pool.Size = 0
+		}
+	}
+
+	return p, nil
+}
+
+//
+// Is the pool open?
+//
+func (p *weightedPool[T]) IsOpen() bool {
+	return p.pools[0].pool.IsOpen()
+}
+
+//
+// Is the pool closed?
+//
+func (p *weightedPool[T]) IsClosed() bool {
+	return !p.IsOpen()
+}
+
+//
+// Number of idle objects in all of the sub-pools
+// Returns -1 if the pool is not open
+//
+func (p *weightedPool[T]) Len() int {
+	if p.IsClosed() {
+		return -1
+	}
+
+	output := 0
+	for _, sub := range p.pools {
+		if count := sub.pool.Len(); count > 0 {
+			output += count
+		}
+	}
+	return output
+}
+
+//
+// Snapshot of the sub-pools' sizes and counters, added together
+//
+func (p *weightedPool[T]) Stats() PoolStats {
+	output := PoolStats{PopFailures: p.rejected.Load()}
+	for _, sub := range p.pools {
+		output = output.plus(sub.pool.Stats())
+	}
+	return output
+}
+
+//
+// State of each url's circuit breaker
+//
+func (p *weightedPool[T]) CircuitStates() map[string]CircuitState {
+	output := make(map[string]CircuitState, len(p.pools))
+	for _, sub := range p.pools {
+		output[sub.url] = sub.breaker.State()
+	}
+	return output
+}
+
+//
+// Objects borrowed from all of the sub-pools, oldest first
+// Returns nil if the pool is not open
+//
+func (p *weightedPool[T]) Outstanding() []BorrowedObject[T] {
+	if p.IsClosed() {
+		return nil
+	}
+
+	output := []BorrowedObject[T]{}
+	for _, sub := range p.pools {
+		output = append(output, sub.pool.Outstanding()...)
+	}
+	sort.SliceStable(output, func(i, j int) bool { return output[i].BorrowedAt.Before(output[j].BorrowedAt) })
+	return output
+}
+
+//
+// Open the sub-pools, closing them again if any of them fails to open
+//
+func (p *weightedPool[T]) Open() error {
+	for _, sub := range p.pools {
+		if err := sub.pool.Open(); nil != err {
+			p.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// Close the sub-pools
+//
+func (p *weightedPool[T]) Close() {
+	for _, sub := range p.pools {
+		sub.pool.Close()
+	}
+}
+
+//
+// Close the sub-pools, and wait for their borrowed objects to be Released
+//
+func (p *weightedPool[T]) Shutdown(ctx context.Context) error {
+	p.Close()
+
+	for _, sub := range p.pools {
+		if err := sub.pool.Shutdown(ctx); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// Get an object without waiting
+// Returns ErrCircuitOpen if every url's circuit breaker is open
+//
+func (p *weightedPool[T]) Get() (T, error) {
+	sub := p.pick()
+	if nil == sub {
+		return p.reject()
+	}
+	return sub.pool.Get()
+}
+
+//
+// Get an object, waiting for any of the sub-pools when all of them are in use
+// Returns ErrCircuitOpen if every url's circuit breaker is open
+//
+func (p *weightedPool[T]) GetContext(ctx context.Context) (T, error) {
+	sub := p.pick()
+	if nil == sub {
+		return p.reject()
+	}
+	if sub.pool.hasFreeSlot() {
+		return sub.pool.GetContext(ctx)
+	}
+	return p.wait(ctx, sub)
+}
+
+//
+// Wait for a slot in any of the sub-pools that can hold objects,
+// the waiters are served in the order they started waiting on each sub-pool
+//
+// The wait is counted in the stats of the sub-pool that frees the slot, or of the picked sub-pool.
+//
+func (p *weightedPool[T]) wait(ctx context.Context, picked *subPool[T]) (obj T, err error) {
+	type waitable struct {
+		sub   *subPool[T]
+		slots chan struct{}
+		idle  chan *poolEntry[T]
+	}
+
+	// One case for the context, then a send on the slots and a receive on done for each sub-pool
+	waitables := []waitable{}
+	cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}}
+	for _, sub := range p.pools {
+		slots, idle, done := sub.pool.channels()
+		if nil == slots || 0 == cap(slots) || !sub.breaker.ready() {
+			continue
+		}
+
+		waitables = append(waitables, waitable{sub, slots, idle})
+		cases = append(cases,
+			reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(slots), Send: reflect.ValueOf(struct{}{})},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)})
+	}
+
+	// Nothing to wait on, let the picked sub-pool fail or wait alone
+	if 0 == len(waitables) {
+		return picked.pool.GetContext(ctx)
+	}
+
+	started := time.Now()
+	chosen, _, _ := reflect.Select(cases)
+	if 0 == chosen {
+		err = contextError(ctx)
+		picked.pool.counters.addWait(started)
+		picked.pool.counters.addPop(err)
+		return obj, err
+	}
+
+	w := waitables[(chosen-1)/2]
+	w.sub.pool.counters.addWait(started)
+	defer func() { w.sub.pool.counters.addPop(err) }()
+
+	// The sub-pool was closed
+	if 0 == chosen%2 {
+		return obj, ErrConnectionIsClosed
+	}
+	return w.sub.pool.take(w.slots, w.idle)
+}
+
+func (p *weightedPool[T]) reject() (T, error) {
+	var zero T
+	p.rejected.Add(1)
+	return zero, ErrCircuitOpen
+}
+
+//
+// Map the keys to the sub-pools with a Ketama continuum, weighted like the sub-pools
+//
+// Every url owns keys, so each sub-pool needs a share of the Size or MaxOpen.
+// An empty pool has nothing to route, and is left alone.
+//
+func (p *weightedPool[T]) useKetama() error {
+	empty := 0
+	for _, sub := range p.pools {
+		if 0 == sub.pool.maxOpen() {
+			empty++
+		}
+	}
+	for _, sub := range p.pools {
+		if 0 == sub.pool.maxOpen() && empty < len(p.pools) {
+			return fmt.Errorf("[Pool][Open] Url[%v] has no share of the connections, Size and MaxOpen must give every Url at least one!", sub.url)
+		}
+	}
+
+	urls := make([]string, len(p.pools))
+	weights := make([]int, len(p.pools))
+	for i, sub := range p.pools {
+		urls[i], weights[i] = sub.url, sub.weight
+	}
+	p.ketama = makeKetamaRing(urls, weights)
+	return nil
+}
+
+//
+// Url of the sub-pool that owns the key
+//
+func (p *weightedPool[T]) UrlOf(key string) string {
+	return p.pools[p.ketama.owner(ketamaHash(key))].url
+}
+
+//
+// Get an object from the sub-pool that owns the key, without waiting
+//
+func (p *weightedPool[T]) GetKey(key string) (T, error) {
+	return p.byUrl[p.UrlOf(key)].pool.Get()
+}
+
+//
+// Get an object from the sub-pool of the url that owns the key, waiting for one to be returned
+//
+func (p *weightedPool[T]) GetKeyContext(ctx context.Context, key string) (T, error) {
+	return p.byUrl[p.UrlOf(key)].pool.GetContext(ctx)
+}
+
+//
+// Return a borrowed object to its sub-pool
+//
+func (p *weightedPool[T]) Release(obj T) {
+	if sub, ok := p.byUrl[p.urlOf(obj)]; ok {
+		sub.pool.Release(obj)
+	}
+}
+
+//
+// Destroy a borrowed object instead of returning it to its sub-pool
+//
+func (p *weightedPool[T]) Discard(obj T) {
+	if sub, ok := p.byUrl[p.urlOf(obj)]; ok {
+		sub.pool.Discard(obj)
+	}
+}
+
+//
+// Pick a sub-pool by weight, from the ones with a free slot if there are any
+// Returns nil if every url's circuit breaker is open
+//
+func (p *weightedPool[T]) pick() *subPool[T] {
+	ready := make([]*subPool[T], 0, len(p.pools))
+	for _, sub := range p.pools {
+		if sub.breaker.ready() {
+			ready = append(ready, sub)
+		}
+	}
+	if 0 == len(ready) {
+		return nil
+	}
+
+	candidates := make([]*subPool[T], 0, len(ready))
+	for _, sub := range ready {
+		if sub.pool.hasFreeSlot() {
+			candidates = append(candidates, sub)
+		}
+	}
+
+	// Everything is in use, wait on one of the sub-pools that can hold objects
+	if 0 == len(candidates) {
+		for _, sub := range ready {
+			if sub.pool.maxOpen() > 0 {
+				candidates = append(candidates, sub)
+			}
+		}
+	}
+	if 0 == len(candidates) {
+		return ready[0]
+	}
+
+	total := 0
+	for _, sub := range candidates {
+		total += sub.weight
+	}
+
+	n := rand.Intn(total)
+	for _, sub := range candidates {
+		if n < sub.weight {
+			return sub
+		}
+		n -= sub.weight
+	}
+	return candidates[len(candidates)-1]
+}
@@ -0,0 +1,216 @@
+package dog_pool
+
+import "context"
+import "testing"
+import "time"
+import "github.com/orfjackal/gospec/src/gospec"
+
+func TestWeightedPoolSpecs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in benchmark mode.")
+		return
+	}
+	r := gospec.NewRunner()
+	r.AddSpec(WeightedPoolSpecs)
+	gospec.MainGoTest(r, t)
+}
+
+func WeightedPoolSpecs(c gospec.Context) {
+	// Fake pool of urls, each object remembers the url it was created for
+	urlOf := func(s *stringWrapper) string { return s.Value }
+	newPool := func(url string) (*Pool[*stringWrapper], *circuitBreaker) {
+		return &Pool[*stringWrapper]{Factory: func() (*stringWrapper, error) { return &stringWrapper{Value: url}, nil }}, nil
+	}
+
+	c.Specify("[WeightedPool] Parses the weight from the url", func() {
+		url, weight, err := parseWeightedUrl("127.0.0.1:6379")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(url, gospec.Equals, "127.0.0.1:6379")
+		c.Expect(weight, gospec.Equals, 1)
+
+		url, weight, err = parseWeightedUrl("127.0.0.1:6379;weight=3")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(url, gospec.Equals, "127.0.0.1:6379")
+		c.Expect(weight, gospec.Equals, 3)
+	})
+
+	c.Specify("[WeightedPool] Invalid weights and options are errors", func() {
+		_, _, err := parseWeightedUrl("127.0.0.1:6379;weight=0")
+		c.Expect(err.Error(), gospec.Equals, "[Pool][Open] Url[127.0.0.1:6379;weight=0] weight must be an integer >= 1!")
+
+		_, _, err = parseWeightedUrl("127.0.0.1:6379;weight=abc")
+		c.Expect(err.Error(), gospec.Equals, "[Pool][Open] Url[127.0.0.1:6379;weight=abc] weight must be an integer >= 1!")
+	})
+
+	c.Specify("[WeightedPool] Only parses a weight after the last ';'", func() {
+		url, weight, err := parseWeightedUrl("redis://:pa;ss@127.0.0.1:6379")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(url, gospec.Equals, "redis://:pa;ss@127.0.0.1:6379")
+		c.Expect(weight, gospec.Equals, 1)
+
+		url, weight, err = parseWeightedUrl("redis://:pa;ss=1@127.0.0.1:6379")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(url, gospec.Equals, "redis://:pa;ss=1@127.0.0.1:6379")
+		c.Expect(weight, gospec.Equals, 1)
+
+		url, weight, err = parseWeightedUrl("redis://:pa;ss@127.0.0.1:6379;weight=3")
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(url, gospec.Equals, "redis://:pa;ss@127.0.0.1:6379")
+		c.Expect(weight, gospec.Equals, 3)
+	})
+
+	c.Specify("[WeightedPool] Splits sizes by weight", func() {
+		c.Expect(splitByWeight(4, []int{3, 1}), gospec.Equals, []int{3, 1})
+		c.Expect(splitByWeight(10, []int{1, 1, 1}), gospec.Equals, []int{4, 3, 3})
+		c.Expect(splitByWeight(5, []int{1, 2}), gospec.Equals, []int{2, 3})
+		c.Expect(splitByWeight(0, []int{1, 2}), gospec.Equals, []int{0, 0})
+	})
+
+	c.Specify("[WeightedPool] Merges repeated urls", func() {
+		pool, err := makeWeightedPool([]string{"a", "b", "a;weight=2"}, 8, 0, 0, urlOf, newPool)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(len(pool.pools), gospec.Equals, 2)
+		c.Expect(pool.pools[0].weight, gospec.Equals, 3)
+		c.Expect(pool.pools[0].pool.Size, gospec.Equals, 6)
+		c.Expect(pool.pools[1].pool.Size, gospec.Equals, 2)
+	})
+
+	c.Specify("[WeightedPool] Invalid sizes are errors", func() {
+		_, err := makeWeightedPool([]string{}, 8, 0, 0, urlOf, newPool)
+		c.Expect(err.Error(), gospec.Equals, "[Pool][Open] Empty Urls!")
+
+		empty, err := makeWeightedPool([]string{}, 0, 0, 0, urlOf, newPool)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(len(empty.pools), gospec.Equals, 1)
+
+		_, err = makeWeightedPool([]string{"a", "b"}, -1, 0, 0, urlOf, newPool)
+		c.Expect(err.Error(), gospec.Equals, "[Pool][Open] Size[-1] must be >= 0!")
+
+		_, err = makeWeightedPool([]string{"a", "b"}, 8, 4, 2, urlOf, newPool)
+		c.Expect(err.Error(), gospec.Equals, "[Pool][Open] MinIdle[4] must be >= 0 and <= MaxOpen[2]!")
+	})
+
+	c.Specify("[WeightedPool] Sub-pools without a share of MaxOpen stay empty", func() {
+		pool, err := makeWeightedPool([]string{"a;weight=3", "b"}, 8, 1, 1, urlOf, newPool)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		defer pool.Close()
+
+		c.Expect(pool.Stats().Size, gospec.Equals, 1)
+
+		value, err := pool.Get()
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(value.Value, gospec.Equals, "a")
+
+		_, err = pool.Get()
+		c.Expect(err, gospec.Equals, ErrNoConnectionsAvailable)
+	})
+
+	c.Specify("[WeightedPool] Gets from the sub-pools with free slots, then fails", func() {
+		pool, err := makeWeightedPool([]string{"a;weight=3", "b"}, 4, 0, 0, urlOf, newPool)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		defer pool.Close()
+
+		counts := map[string]int{}
+		values := []*stringWrapper{}
+		for i := 0; i < 4; i++ {
+			value, err := pool.Get()
+			c.Expect(err, gospec.Equals, nil)
+			counts[value.Value]++
+			values = append(values, value)
+		}
+		c.Expect(counts, gospec.Equals, map[string]int{"a": 3, "b": 1})
+
+		_, err = pool.Get()
+		c.Expect(err, gospec.Equals, ErrNoConnectionsAvailable)
+
+		// Released to their own sub-pools
+		for _, value := range values {
+			pool.Release(value)
+		}
+		c.Expect(pool.pools[0].pool.Len(), gospec.Equals, 3)
+		c.Expect(pool.pools[1].pool.Len(), gospec.Equals, 1)
+
+		stats := pool.Stats()
+		c.Expect(stats.Size, gospec.Equals, 4)
+		c.Expect(stats.PopSuccesses, gospec.Equals, uint64(4))
+		c.Expect(stats.PopFailures, gospec.Equals, uint64(1))
+	})
+
+	c.Specify("[WeightedPool] Picks the sub-pools by weight", func() {
+		pool, err := makeWeightedPool([]string{"a;weight=3", "b"}, 40, 0, 0, urlOf, newPool)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		defer pool.Close()
+
+		counts := map[string]int{}
+		for i := 0; i < 1000; i++ {
+			value, err := pool.Get()
+			c.Expect(err, gospec.Equals, nil)
+			counts[value.Value]++
+			pool.Release(value)
+		}
+
+		// 75% expected, with plenty of room for randomness
+		c.Expect(counts["a"], gospec.Satisfies, counts["a"] > 650 && counts["a"] < 850)
+	})
+
+	c.Specify("[WeightedPool] Waits on every sub-pool when all of them are in use", func() {
+		// Nearly every wait picks "a", but the object comes back to "b"
+		pool, err := makeWeightedPool([]string{"a;weight=99", "b"}, 100, 0, 0, urlOf, newPool)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		defer pool.Close()
+
+		var b *stringWrapper
+		for i := 0; i < 100; i++ {
+			value, err := pool.Get()
+			c.Expect(err, gospec.Equals, nil)
+			if "b" == value.Value {
+				b = value
+			}
+		}
+		c.Expect(b, gospec.Satisfies, nil != b)
+
+		for i := 0; i < 5; i++ {
+			go func() {
+				time.Sleep(time.Duration(10) * time.Millisecond)
+				pool.Release(b)
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			value, err := pool.GetContext(ctx)
+			cancel()
+			c.Expect(err, gospec.Equals, nil)
+			c.Expect(value, gospec.Equals, b)
+		}
+
+		stats := pool.Stats()
+		c.Expect(stats.Waits, gospec.Equals, uint64(5))
+		c.Expect(stats.PopSuccesses, gospec.Equals, uint64(105))
+	})
+
+	c.Specify("[WeightedPool] Waiting callers time out, or wake up when the pool is closed", func() {
+		pool, err := makeWeightedPool([]string{"a", "b"}, 2, 0, 0, urlOf, newPool)
+		c.Expect(err, gospec.Equals, nil)
+		c.Expect(pool.Open(), gospec.Equals, nil)
+		defer pool.Close()
+
+		pool.Get()
+		pool.Get()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Millisecond)
+		defer cancel()
+		_, err = pool.GetContext(ctx)
+		c.Expect(err, gospec.Equals, ErrPoolTimeout)
+		c.Expect(pool.Stats().PopFailures, gospec.Equals, uint64(1))
+
+		go func() {
+			time.Sleep(time.Duration(10) * time.Millisecond)
+			pool.Close()
+		}()
+		_, err = pool.GetContext(context.Background())
+		c.Expect(err, gospec.Equals, ErrConnectionIsClosed)
+	})
+}